@@ -22,21 +22,27 @@ type MetricsOptions = mtypes.MetricsRequest
 //
 // https://documentation.mailgun.com/docs/mailgun/api-reference/openapi-final/tag/Metrics/
 func (mg *Client) ListMetrics(opts MetricsOptions) (*MetricsIterator, error) {
+	return mg.newMetricsIterator(generateApiUrl(mg, 1, metricsEndpoint), opts), nil
+}
+
+func (mg *Client) newMetricsIterator(uri string, opts MetricsOptions) *MetricsIterator {
 	if opts.Pagination.Limit == 0 {
 		opts.Pagination.Limit = 10
 	}
 
-	req := newHTTPRequest(generateApiUrl(mg, 1, metricsEndpoint))
+	req := newHTTPRequest(uri)
 	req.setClient(mg.HTTPClient())
 	req.setBasicAuth(basicAuthUser, mg.APIKey())
 
 	return &MetricsIterator{
+		mg:   mg,
 		opts: opts,
 		req:  req,
-	}, nil
+	}
 }
 
 type MetricsIterator struct {
+	mg   Mailgun
 	opts MetricsOptions
 	req  *httpRequest
 	err  error
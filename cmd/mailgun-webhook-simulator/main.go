@@ -0,0 +1,106 @@
+// Command mailgun-webhook-simulator signs and posts sample webhooks to an endpoint, as Mailgun does.
+//
+//	mailgun-webhook-simulator -url http://localhost:8080/webhooks -key $MG_WEBHOOK_SIGNING_KEY \
+//		-events delivered,failed -retries 1s,5s -faults ,bad-signature,replay
+//
+// It exits with status 1 if a valid webhook is not accepted with 200 OK,
+// or if a faulty webhook is not rejected with 406 Not Acceptable.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/webhooks"
+)
+
+func main() {
+	url := flag.String("url", "", "URL of the webhook endpoint (required)")
+	key := flag.String("key", os.Getenv("MG_WEBHOOK_SIGNING_KEY"), "webhook signing key, defaults to $MG_WEBHOOK_SIGNING_KEY")
+	names := flag.String("events", "", "comma-separated event names, defaults to every event")
+	retries := flag.String("retries", "", "comma-separated delays between retries, e.g. 1s,5s,30s")
+	faults := flag.String("faults", "", "comma-separated failure schedule applied in turn: "+
+		"empty (none), bad-signature, expired-timestamp, replay or malformed")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of each request")
+	flag.Parse()
+
+	if *url == "" || *key == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := webhooks.SimulatorOptions{
+		HTTPClient: &http.Client{Timeout: *timeout},
+	}
+	for _, delay := range split(*retries) {
+		d, err := time.ParseDuration(delay)
+		if err != nil {
+			fatalf("invalid retry delay '%s': %s", delay, err)
+		}
+		opts.RetrySchedule = append(opts.RetrySchedule, d)
+	}
+	if *faults != "" {
+		for _, name := range strings.Split(*faults, ",") {
+			fault, err := webhooks.ParseFault(strings.TrimSpace(name))
+			if err != nil {
+				fatalf("invalid fault: %s", err)
+			}
+			opts.Faults = append(opts.Faults, fault)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	sim := webhooks.NewSimulator(*key, &opts)
+	deliveries, err := sim.SendAll(ctx, *url, split(*names)...)
+
+	failed := err != nil
+	for _, d := range deliveries {
+		want := http.StatusOK
+		if d.Fault != webhooks.FaultNone {
+			want = http.StatusNotAcceptable
+		}
+		result := "ok"
+		if d.Status() != want {
+			result = fmt.Sprintf("FAIL: want %d", want)
+			if d.Err != nil {
+				result += ": " + d.Err.Error()
+			}
+			failed = true
+		}
+		fault := string(d.Fault)
+		if fault == "" {
+			fault = "none"
+		}
+		fmt.Printf("%-26s fault=%-18s attempts=%v %s\n", d.Event.GetName(), fault, d.Attempts, result)
+	}
+
+	if err != nil {
+		fatalf("%s", err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func split(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,439 @@
+package mailgun
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// ErrInvalidCursor is returned by the Resume methods when a cursor is malformed, was tampered with,
+// was created by a client with another API key or API base, or belongs to another kind of list.
+var ErrInvalidCursor = errors.New("invalid paging cursor")
+
+const (
+	cursorBounces          = "bounces"
+	cursorUnsubscribes     = "unsubscribes"
+	cursorComplaints       = "complaints"
+	cursorTags             = "tags"
+	cursorEvents           = "events"
+	cursorTemplates        = "templates"
+	cursorTemplateVersions = "template_versions"
+	cursorMailingLists     = "mailing_lists"
+	cursorMembers          = "members"
+	cursorIPWarmups        = "ip_warmups"
+	cursorDomains          = "domains"
+	cursorRoutes           = "routes"
+	cursorCredentials      = "credentials"
+	cursorIPDomains        = "ip_domains"
+	cursorSubaccounts      = "subaccounts"
+	cursorDomainKeys       = "domain_keys"
+	cursorAllDomainsKeys   = "all_domains_keys"
+	cursorMetrics          = "metrics"
+	cursorMonitoredDomains = "monitored_domains"
+)
+
+// cursorState is the position of an iterator, serialized in a cursor.
+type cursorState struct {
+	Kind string `json:"k"`
+	// Paging is the position of the iterators paging by URL.
+	Paging *mtypes.Paging `json:"p,omitempty"`
+	// URL, Offset and Limit are the position of the iterators paging by skip and limit.
+	URL    string `json:"u,omitempty"`
+	Offset int    `json:"o,omitempty"`
+	Limit  int    `json:"l,omitempty"`
+	// Options are the list options of the iterator, if any.
+	Options json.RawMessage `json:"x,omitempty"`
+}
+
+// paging returns the paging of the state, empty if there is none.
+func (st cursorState) paging() mtypes.Paging {
+	if st.Paging == nil {
+		return mtypes.Paging{}
+	}
+	return *st.Paging
+}
+
+// encodeCursor returns the state as base64url(JSON) "." base64url(HMAC-SHA256), along with the options
+// of the iterator if opts is not nil. The HMAC key is derived from the API key, so a cursor cannot
+// be forged without it.
+func encodeCursor(mg Mailgun, st cursorState, opts any) (string, error) {
+	if opts != nil {
+		var err error
+		if st.Options, err = json.Marshal(opts); err != nil {
+			return "", fmt.Errorf("while encoding %s cursor options: %w", st.Kind, err)
+		}
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return "", fmt.Errorf("while encoding %s cursor: %w", st.Kind, err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(cursorMAC(mg, payload)), nil
+}
+
+// resume decodes and validates a cursor of the kind, decodes its options into opts if not nil,
+// and returns the iterator created at its position by newIterator.
+func resume[T any](mg Mailgun, kind, cursor string, opts any, newIterator func(st cursorState) *T) (*T, error) {
+	st, err := decodeCursor(mg, kind, cursor)
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && len(st.Options) != 0 {
+		if err := json.Unmarshal(st.Options, opts); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return newIterator(st), nil
+}
+
+func decodeCursor(mg Mailgun, kind, cursor string) (cursorState, error) {
+	var st cursorState
+
+	payload, sig, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return st, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, cursorMAC(mg, payload)) {
+		return st, ErrInvalidCursor
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return st, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, ErrInvalidCursor
+	}
+	if st.Kind != kind {
+		return st, fmt.Errorf("%w: cursor of %s, expected %s", ErrInvalidCursor, st.Kind, kind)
+	}
+
+	// The URLs must point to the API of this client.
+	urls := []string{st.URL}
+	if st.Paging != nil {
+		urls = append(urls, st.Paging.First, st.Paging.Next, st.Paging.Previous, st.Paging.Last)
+	}
+	for _, u := range urls {
+		if u != "" && !strings.HasPrefix(u, mg.APIBase()+"/") {
+			return st, ErrInvalidCursor
+		}
+	}
+	return st, nil
+}
+
+func cursorMAC(mg Mailgun, payload string) []byte {
+	key := sha256.Sum256([]byte("mailgun-go paging cursor\x00" + mg.APIKey()))
+	h := hmac.New(sha256.New, key[:])
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+//
+// Paging by URL
+//
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator.
+// Use ResumeBounces to continue the iteration from it, e.g. in another HTTP request.
+func (ci *BouncesIterator) Cursor() (string, error) {
+	return encodeCursor(ci.mg, cursorState{Kind: cursorBounces, Paging: &ci.Paging}, nil)
+}
+
+// ResumeBounces creates an iterator at the position of a cursor returned by `BouncesIterator.Cursor()`.
+func (mg *Client) ResumeBounces(cursor string) (*BouncesIterator, error) {
+	return resume(mg, cursorBounces, cursor, nil, func(st cursorState) *BouncesIterator {
+		return &BouncesIterator{mg: mg, BouncesListResponse: mtypes.BouncesListResponse{Paging: st.paging()}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeUnsubscribes.
+func (ci *UnsubscribesIterator) Cursor() (string, error) {
+	return encodeCursor(ci.mg, cursorState{Kind: cursorUnsubscribes, Paging: &ci.Paging}, nil)
+}
+
+// ResumeUnsubscribes creates an iterator at the position of a cursor returned by `UnsubscribesIterator.Cursor()`.
+func (mg *Client) ResumeUnsubscribes(cursor string) (*UnsubscribesIterator, error) {
+	return resume(mg, cursorUnsubscribes, cursor, nil, func(st cursorState) *UnsubscribesIterator {
+		return &UnsubscribesIterator{mg: mg, ListUnsubscribesResponse: mtypes.ListUnsubscribesResponse{Paging: st.paging()}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeComplaints.
+func (ci *ComplaintsIterator) Cursor() (string, error) {
+	return encodeCursor(ci.mg, cursorState{Kind: cursorComplaints, Paging: &ci.Paging}, nil)
+}
+
+// ResumeComplaints creates an iterator at the position of a cursor returned by `ComplaintsIterator.Cursor()`.
+func (mg *Client) ResumeComplaints(cursor string) (*ComplaintsIterator, error) {
+	return resume(mg, cursorComplaints, cursor, nil, func(st cursorState) *ComplaintsIterator {
+		return &ComplaintsIterator{mg: mg, ComplaintsResponse: mtypes.ComplaintsResponse{Paging: st.paging()}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeTags.
+func (ti *TagIterator) Cursor() (string, error) {
+	return encodeCursor(ti.mg, cursorState{Kind: cursorTags, Paging: &ti.Paging}, nil)
+}
+
+// ResumeTags creates an iterator at the position of a cursor returned by `TagIterator.Cursor()`.
+func (mg *Client) ResumeTags(cursor string) (*TagIterator, error) {
+	return resume(mg, cursorTags, cursor, nil, func(st cursorState) *TagIterator {
+		return &TagIterator{mg: mg, TagsResponse: mtypes.TagsResponse{Paging: st.paging()}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeEvents.
+func (ei *EventIterator) Cursor() (string, error) {
+	paging := mtypes.Paging(ei.Paging)
+	return encodeCursor(ei.mg, cursorState{Kind: cursorEvents, Paging: &paging}, nil)
+}
+
+// ResumeEvents creates an iterator at the position of a cursor returned by `EventIterator.Cursor()`.
+func (mg *Client) ResumeEvents(cursor string) (*EventIterator, error) {
+	return resume(mg, cursorEvents, cursor, nil, func(st cursorState) *EventIterator {
+		return &EventIterator{mg: mg, Response: events.Response{Paging: events.Paging(st.paging())}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeTemplates.
+func (ti *TemplatesIterator) Cursor() (string, error) {
+	return encodeCursor(ti.mg, cursorState{Kind: cursorTemplates, Paging: &ti.Paging}, nil)
+}
+
+// ResumeTemplates creates an iterator at the position of a cursor returned by `TemplatesIterator.Cursor()`.
+func (mg *Client) ResumeTemplates(cursor string) (*TemplatesIterator, error) {
+	return resume(mg, cursorTemplates, cursor, nil, func(st cursorState) *TemplatesIterator {
+		return &TemplatesIterator{mg: mg, ListTemplateResp: mtypes.ListTemplateResp{Paging: st.paging()}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeTemplateVersions.
+func (li *TemplateVersionsIterator) Cursor() (string, error) {
+	return encodeCursor(li.mg, cursorState{Kind: cursorTemplateVersions, Paging: &li.Paging}, nil)
+}
+
+// ResumeTemplateVersions creates an iterator at the position of a cursor returned by `TemplateVersionsIterator.Cursor()`.
+func (mg *Client) ResumeTemplateVersions(cursor string) (*TemplateVersionsIterator, error) {
+	return resume(mg, cursorTemplateVersions, cursor, nil, func(st cursorState) *TemplateVersionsIterator {
+		return &TemplateVersionsIterator{mg: mg, TemplateVersionListResp: mtypes.TemplateVersionListResp{Paging: st.paging()}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeMailingLists.
+func (li *ListsIterator) Cursor() (string, error) {
+	return encodeCursor(li.mg, cursorState{Kind: cursorMailingLists, Paging: &li.Paging}, nil)
+}
+
+// ResumeMailingLists creates an iterator at the position of a cursor returned by `ListsIterator.Cursor()`.
+func (mg *Client) ResumeMailingLists(cursor string) (*ListsIterator, error) {
+	return resume(mg, cursorMailingLists, cursor, nil, func(st cursorState) *ListsIterator {
+		return &ListsIterator{mg: mg, ListMailingListsResponse: mtypes.ListMailingListsResponse{Paging: st.paging()}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeMembers.
+func (li *MemberListIterator) Cursor() (string, error) {
+	return encodeCursor(li.mg, cursorState{Kind: cursorMembers, Paging: &li.Paging}, nil)
+}
+
+// ResumeMembers creates an iterator at the position of a cursor returned by `MemberListIterator.Cursor()`.
+func (mg *Client) ResumeMembers(cursor string) (*MemberListIterator, error) {
+	return resume(mg, cursorMembers, cursor, nil, func(st cursorState) *MemberListIterator {
+		return &MemberListIterator{mg: mg, MemberListResponse: mtypes.MemberListResponse{Paging: st.paging()}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeIPWarmups.
+func (ri *IPWarmupsIterator) Cursor() (string, error) {
+	return encodeCursor(ri.mg, cursorState{Kind: cursorIPWarmups, Paging: &ri.Paging}, nil)
+}
+
+// ResumeIPWarmups creates an iterator at the position of a cursor returned by `IPWarmupsIterator.Cursor()`.
+func (mg *Client) ResumeIPWarmups(cursor string) (*IPWarmupsIterator, error) {
+	return resume(mg, cursorIPWarmups, cursor, nil, func(st cursorState) *IPWarmupsIterator {
+		return &IPWarmupsIterator{mg: mg, ListIPWarmupsResponse: mtypes.ListIPWarmupsResponse{Paging: st.paging()}}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeDomainKeys.
+func (iter *DomainKeysIterator) Cursor() (string, error) {
+	paging := iter.Paging
+	if iter.isFirst {
+		paging = mtypes.Paging{Next: iter.uri}
+	}
+	return encodeCursor(iter.mg, cursorState{Kind: cursorDomainKeys, Paging: &paging}, iter.domain)
+}
+
+// ResumeDomainKeys creates an iterator at the position of a cursor returned by `DomainKeysIterator.Cursor()`.
+func (mg *Client) ResumeDomainKeys(cursor string) (*DomainKeysIterator, error) {
+	var domain string
+	return resume(mg, cursorDomainKeys, cursor, &domain, func(st cursorState) *DomainKeysIterator {
+		return &DomainKeysIterator{
+			ListDomainKeysResponse: mtypes.ListDomainKeysResponse{Paging: st.paging()},
+			mg:                     mg,
+			uri:                    generateApiUrl(mg, 4, generateListDomainKeysApiUrl(domainsEndpoint, domain)),
+			domain:                 domain,
+		}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeAllDomainsKeys.
+func (ri *AllDomainsKeysIterator) Cursor() (string, error) {
+	return encodeCursor(ri.mg, cursorState{Kind: cursorAllDomainsKeys, Paging: &ri.Paging, Limit: ri.limit}, nil)
+}
+
+// ResumeAllDomainsKeys creates an iterator at the position of a cursor returned by `AllDomainsKeysIterator.Cursor()`.
+func (mg *Client) ResumeAllDomainsKeys(cursor string) (*AllDomainsKeysIterator, error) {
+	return resume(mg, cursorAllDomainsKeys, cursor, nil, func(st cursorState) *AllDomainsKeysIterator {
+		return &AllDomainsKeysIterator{
+			ListAllDomainsKeysResponse: mtypes.ListAllDomainsKeysResponse{Paging: st.paging(), TotalCount: -1},
+			mg:                         mg,
+			url:                        generateApiUrl(mg, 1, dkimEndpoint),
+			limit:                      st.Limit,
+		}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, including
+// its options. Use ResumeMonitoredDomains to continue the iteration from it.
+func (iter *MonitoredDomainsIterator) Cursor() (string, error) {
+	st := cursorState{Kind: cursorMonitoredDomains, URL: iter.req.URL}
+	if !iter.isFirst {
+		st.URL = ""
+		if iter.resp.Paging != nil {
+			st.URL = iter.resp.Paging.Next
+		}
+	}
+	return encodeCursor(iter.mg, st, iter.opts)
+}
+
+// ResumeMonitoredDomains creates an iterator at the position of a cursor returned by
+// `MonitoredDomainsIterator.Cursor()`.
+func (mg *Client) ResumeMonitoredDomains(cursor string) (*MonitoredDomainsIterator, error) {
+	var opts ListMonitoredDomainsOptions
+	return resume(mg, cursorMonitoredDomains, cursor, &opts, func(st cursorState) *MonitoredDomainsIterator {
+		// An empty URL is a cursor taken after the last page.
+		return mg.newMonitoredDomainsIterator(st.URL, opts)
+	})
+}
+
+//
+// Paging by skip and limit
+//
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, including
+// its list options. Use ResumeDomains to continue the iteration from it.
+func (ri *DomainsIterator) Cursor() (string, error) {
+	var opts any
+	if ri.opts != nil {
+		opts = ri.opts
+	}
+	return encodeCursor(ri.mg, cursorState{Kind: cursorDomains, URL: ri.url, Offset: ri.offset, Limit: ri.limit}, opts)
+}
+
+// ResumeDomains creates an iterator at the position of a cursor returned by `DomainsIterator.Cursor()`.
+func (mg *Client) ResumeDomains(cursor string) (*DomainsIterator, error) {
+	var opts *ListDomainsOptions
+	return resume(mg, cursorDomains, cursor, &opts, func(st cursorState) *DomainsIterator {
+		return &DomainsIterator{
+			mg:                  mg,
+			url:                 st.URL,
+			offset:              st.Offset,
+			limit:               st.Limit,
+			opts:                opts,
+			ListDomainsResponse: mtypes.ListDomainsResponse{TotalCount: -1},
+		}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeRoutes.
+func (ri *RoutesIterator) Cursor() (string, error) {
+	return encodeCursor(ri.mg, cursorState{Kind: cursorRoutes, URL: ri.url, Offset: ri.offset, Limit: ri.limit}, nil)
+}
+
+// ResumeRoutes creates an iterator at the position of a cursor returned by `RoutesIterator.Cursor()`.
+func (mg *Client) ResumeRoutes(cursor string) (*RoutesIterator, error) {
+	return resume(mg, cursorRoutes, cursor, nil, func(st cursorState) *RoutesIterator {
+		return &RoutesIterator{
+			mg:                 mg,
+			url:                st.URL,
+			offset:             st.Offset,
+			limit:              st.Limit,
+			RoutesListResponse: mtypes.RoutesListResponse{TotalCount: -1},
+		}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeCredentials.
+func (ri *CredentialsIterator) Cursor() (string, error) {
+	return encodeCursor(ri.mg, cursorState{Kind: cursorCredentials, URL: ri.url, Offset: ri.offset, Limit: ri.limit}, nil)
+}
+
+// ResumeCredentials creates an iterator at the position of a cursor returned by `CredentialsIterator.Cursor()`.
+func (mg *Client) ResumeCredentials(cursor string) (*CredentialsIterator, error) {
+	return resume(mg, cursorCredentials, cursor, nil, func(st cursorState) *CredentialsIterator {
+		return &CredentialsIterator{
+			mg:                      mg,
+			url:                     st.URL,
+			offset:                  st.Offset,
+			limit:                   st.Limit,
+			CredentialsListResponse: mtypes.CredentialsListResponse{TotalCount: -1},
+		}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeIPDomains.
+func (ri *IPDomainsIterator) Cursor() (string, error) {
+	return encodeCursor(ri.mg, cursorState{Kind: cursorIPDomains, URL: ri.url, Offset: ri.offset, Limit: ri.limit}, nil)
+}
+
+// ResumeIPDomains creates an iterator at the position of a cursor returned by `IPDomainsIterator.Cursor()`.
+func (mg *Client) ResumeIPDomains(cursor string) (*IPDomainsIterator, error) {
+	return resume(mg, cursorIPDomains, cursor, nil, func(st cursorState) *IPDomainsIterator {
+		return &IPDomainsIterator{
+			mg:                    mg,
+			url:                   st.URL,
+			offset:                st.Offset,
+			limit:                 st.Limit,
+			ListIPDomainsResponse: mtypes.ListIPDomainsResponse{TotalCount: -1},
+		}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, see ResumeSubaccounts.
+func (ri *SubaccountsIterator) Cursor() (string, error) {
+	return encodeCursor(ri.mg, cursorState{Kind: cursorSubaccounts, URL: ri.url, Offset: ri.offset, Limit: ri.limit}, nil)
+}
+
+// ResumeSubaccounts creates an iterator at the position of a cursor returned by `SubaccountsIterator.Cursor()`.
+func (mg *Client) ResumeSubaccounts(cursor string) (*SubaccountsIterator, error) {
+	return resume(mg, cursorSubaccounts, cursor, nil, func(st cursorState) *SubaccountsIterator {
+		return &SubaccountsIterator{
+			mg:                      mg,
+			url:                     st.URL,
+			offset:                  st.Offset,
+			limit:                   st.Limit,
+			ListSubaccountsResponse: mtypes.ListSubaccountsResponse{Total: -1},
+		}
+	})
+}
+
+// Cursor returns an opaque, tamper-evident token of the position of the iterator, including
+// its options. Use ResumeMetrics to continue the iteration from it.
+func (iter *MetricsIterator) Cursor() (string, error) {
+	return encodeCursor(iter.mg, cursorState{Kind: cursorMetrics, URL: iter.req.URL}, iter.opts)
+}
+
+// ResumeMetrics creates an iterator at the position of a cursor returned by `MetricsIterator.Cursor()`.
+func (mg *Client) ResumeMetrics(cursor string) (*MetricsIterator, error) {
+	var opts MetricsOptions
+	return resume(mg, cursorMetrics, cursor, &opts, func(st cursorState) *MetricsIterator {
+		return mg.newMetricsIterator(st.URL, opts)
+	})
+}
@@ -0,0 +1,184 @@
+package mailgun_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeBounces(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(server.URL()))
+	ctx := context.Background()
+
+	for _, address := range []string{"cursor1@example.com", "cursor2@example.com", "cursor3@example.com"} {
+		require.NoError(t, mg.AddBounce(ctx, testDomain, address, "550", "No such mailbox"))
+	}
+
+	var first, want, got []mtypes.Bounce
+	it := mg.ListBounces(testDomain, &mailgun.ListOptions{Limit: 1})
+	require.True(t, it.Next(ctx, &first))
+	cursor, err := it.Cursor()
+	require.NoError(t, err)
+	require.True(t, it.Next(ctx, &want))
+
+	resumed, err := mg.ResumeBounces(cursor)
+	require.NoError(t, err)
+	require.True(t, resumed.Next(ctx, &got))
+	assert.Equal(t, want, got)
+	assert.NotEqual(t, first, got)
+}
+
+func TestResumeDomains(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(server.URL()))
+	ctx := context.Background()
+
+	for _, name := range []string{"cursor1.example.com", "cursor2.example.com"} {
+		_, err := mg.CreateDomain(ctx, name, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = mg.DeleteDomain(ctx, name) })
+	}
+
+	var first, want, got []mtypes.Domain
+	it := mg.ListDomains(&mailgun.ListDomainsOptions{Limit: 1})
+	require.True(t, it.Next(ctx, &first))
+	cursor, err := it.Cursor()
+	require.NoError(t, err)
+	require.True(t, it.Next(ctx, &want))
+
+	resumed, err := mg.ResumeDomains(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resumed.Offset())
+	require.True(t, resumed.Next(ctx, &got))
+	assert.Equal(t, want, got)
+}
+
+func TestResumeSubaccounts(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(server.URL()))
+	ctx := context.Background()
+
+	var first, want, got []mtypes.Subaccount
+	it := mg.ListSubaccounts(&mailgun.ListSubaccountsOptions{Limit: 1})
+	require.True(t, it.Next(ctx, &first))
+	cursor, err := it.Cursor()
+	require.NoError(t, err)
+	require.True(t, it.Next(ctx, &want))
+
+	resumed, err := mg.ResumeSubaccounts(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resumed.Offset())
+	require.True(t, resumed.Next(ctx, &got))
+	assert.Equal(t, want, got)
+}
+
+func TestResumeWithOptions(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(server.URL()))
+	ctx := context.Background()
+
+	t.Run("metrics", func(t *testing.T) {
+		it, err := mg.ListMetrics(mailgun.MetricsOptions{
+			Resolution: mtypes.ResolutionDay,
+			Metrics:    []string{"sent_count"},
+		})
+		require.NoError(t, err)
+		var page mtypes.MetricsResponse
+		it.Next(ctx, &page)
+		require.NoError(t, it.Err())
+		cursor, err := it.Cursor()
+		require.NoError(t, err)
+
+		resumed, err := mg.ResumeMetrics(cursor)
+		require.NoError(t, err)
+		// The options, including the skip of the next page, are restored.
+		resumedCursor, err := resumed.Cursor()
+		require.NoError(t, err)
+		assert.Equal(t, cursor, resumedCursor)
+		resumed.Next(ctx, &page)
+		require.NoError(t, resumed.Err())
+		assert.Len(t, page.Items, 1)
+	})
+
+	t.Run("monitored domains", func(t *testing.T) {
+		it, err := mg.ListMonitoredDomains(mailgun.ListMonitoredDomainsOptions{Limit: ptr(5)})
+		require.NoError(t, err)
+		cursor, err := it.Cursor()
+		require.NoError(t, err)
+
+		resumed, err := mg.ResumeMonitoredDomains(cursor)
+		require.NoError(t, err)
+		resumedCursor, err := resumed.Cursor()
+		require.NoError(t, err)
+		assert.Equal(t, cursor, resumedCursor)
+	})
+
+	t.Run("domain keys", func(t *testing.T) {
+		it := mg.ListDomainKeys(testDomain)
+		cursor, err := it.Cursor()
+		require.NoError(t, err)
+
+		resumed, err := mg.ResumeDomainKeys(cursor)
+		require.NoError(t, err)
+		resumedCursor, err := resumed.Cursor()
+		require.NoError(t, err)
+		assert.Equal(t, cursor, resumedCursor)
+
+		var want, got []mtypes.DomainKey
+		it.Next(ctx, &want)
+		require.NoError(t, it.Err())
+		resumed.Next(ctx, &got)
+		require.NoError(t, resumed.Err())
+		assert.NotEmpty(t, got)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("all domains keys", func(t *testing.T) {
+		var first, want, got []mtypes.DomainKey
+		it := mg.ListAllDomainsKeys(&mailgun.ListAllDomainsKeysOptions{Limit: 1})
+		require.True(t, it.Next(ctx, &first))
+		cursor, err := it.Cursor()
+		require.NoError(t, err)
+		it.Next(ctx, &want)
+		require.NoError(t, it.Err())
+
+		resumed, err := mg.ResumeAllDomainsKeys(cursor)
+		require.NoError(t, err)
+		resumed.Next(ctx, &got)
+		require.NoError(t, resumed.Err())
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestResumeInvalidCursor(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(server.URL()))
+
+	cursor, err := mg.ListRoutes(&mailgun.ListOptions{Limit: 10}).Cursor()
+	require.NoError(t, err)
+	_, err = mg.ResumeRoutes(cursor)
+	require.NoError(t, err)
+
+	payload, sig, _ := strings.Cut(cursor, ".")
+	tampered := strings.Replace(payload, payload[:2], "ey", 1) + "x." + sig
+
+	other := mailgun.NewMailgun("other-key")
+	require.NoError(t, other.SetAPIBase(server.URL()))
+
+	for name, resume := range map[string]func() error{
+		"tampered":   func() error { _, err := mg.ResumeRoutes(tampered); return err },
+		"garbage":    func() error { _, err := mg.ResumeRoutes("garbage"); return err },
+		"other kind": func() error { _, err := mg.ResumeCredentials(cursor); return err },
+		"other key":  func() error { _, err := other.ResumeRoutes(cursor); return err },
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, resume(), mailgun.ErrInvalidCursor)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"time"
 
@@ -25,8 +26,22 @@ type ListEventOptions struct {
 	Limit int
 	// Filter allows the caller to provide more specialized filters on the query.
 	// Consult the Mailgun documentation for more details.
-	Filter       map[string]string
+	Filter map[string]string
+	// FilterBy is a typed alternative to Filter, validated before any request is made.
+	// Fields set by FilterBy take precedence over Filter.
+	FilterBy *EventFilter
+	// PollInterval is how often PollEvents() fetches new events. Defaults to 15 seconds.
 	PollInterval time.Duration
+	// Checkpoint, if set, makes PollEvents() resume from the saved position and save
+	// the position once the returned events have been processed, see `EventPoller.Commit()`.
+	Checkpoint EventCheckpointStore
+	// DedupeWindow is how long PollEvents() remembers the IDs of returned events to drop
+	// duplicates from overlapping pages. Defaults to 10 minutes and is never less than SettleDelay.
+	DedupeWindow time.Duration
+	// SettleDelay makes PollEvents() re-read the events of the last SettleDelay each time it
+	// reaches the most recent events, to catch the events Mailgun stores late, e.g. 30 * time.Minute.
+	// Re-read events are de-duplicated. Disabled by default.
+	SettleDelay time.Duration
 }
 
 // EventIterator maintains the state necessary for paging though small parcels of a larger set of events.
@@ -62,10 +77,18 @@ func (mg *Client) listEvents(url string, opts *ListEventOptions) *EventIterator
 		if !opts.End.IsZero() {
 			req.addParameter("end", formatMailgunTime(opts.End))
 		}
-		if opts.Filter != nil {
-			for k, v := range opts.Filter {
-				req.addParameter(k, v)
+		filter := opts.Filter
+		if opts.FilterBy != nil {
+			typed, err := opts.FilterBy.Build()
+			if err != nil {
+				return &EventIterator{mg: mg, err: err}
 			}
+			filter = make(map[string]string, len(opts.Filter)+len(typed))
+			maps.Copy(filter, opts.Filter)
+			maps.Copy(filter, typed)
+		}
+		for k, v := range filter {
+			req.addParameter(k, v)
 		}
 	}
 	url, err := req.generateUrlWithParameters()
@@ -171,11 +194,16 @@ func (ei *EventIterator) fetch(ctx context.Context, url string) error {
 
 // EventPoller maintains the state necessary for polling events
 type EventPoller struct {
-	it        *EventIterator
-	opts      ListEventOptions
-	beginTime time.Time
-	mg        Mailgun
-	err       error
+	it     *EventIterator
+	opts   ListEventOptions
+	domain string
+	mg     Mailgun
+	err    error
+
+	loaded  bool
+	latest  time.Time
+	seen    map[string]time.Time
+	pending *EventCheckpoint
 }
 
 // PollEvents polls the events api and return new events as they occur
@@ -184,7 +212,9 @@ type EventPoller struct {
 //		// Only events with a timestamp after this date/time will be returned
 //		Begin:        time.Now().Add(time.Second * -3),
 //		// How often we poll the api for new events
-//		PollInterval: time.Second * 4
+//		PollInterval: time.Second * 4,
+//		// Resume from the last processed event after a restart
+//		Checkpoint:   mailgun.NewFileEventCheckpointStore("events.checkpoint"),
 //	})
 //
 //	var events []Event
@@ -214,10 +244,16 @@ func (mg *Client) PollEvents(domain string, opts *ListEventOptions) *EventPoller
 		opts.PollInterval = time.Duration(time.Second * 15)
 	}
 
+	if opts.DedupeWindow == 0 {
+		opts.DedupeWindow = 10 * time.Minute
+	}
+	opts.DedupeWindow = max(opts.DedupeWindow, opts.SettleDelay)
+
 	return &EventPoller{
-		it:   mg.ListEvents(domain, opts),
-		opts: *opts,
-		mg:   mg,
+		it:     mg.ListEvents(domain, opts),
+		opts:   *opts,
+		domain: domain,
+		mg:     mg,
 	}
 }
 
@@ -226,54 +262,144 @@ func (ep *EventPoller) Err() error {
 	return ep.err
 }
 
+// Poll blocks until new events appear or the context is cancelled. Events already
+// returned within the de-duplication window are never returned again.
+//
+// Poll saves the checkpoint of the previously returned events before fetching new ones,
+// so the events returned by Poll must be processed before calling Poll again.
 func (ep *EventPoller) Poll(ctx context.Context, ee *[]events.Event) bool {
-	var currentPage string
-	var results []events.Event
-
-	if ep.opts.Begin.IsZero() {
-		ep.beginTime = time.Now().UTC()
+	if ep.err != nil {
+		return false
+	}
+	if !ep.loaded {
+		if ep.err = ep.load(ctx); ep.err != nil {
+			return false
+		}
+	}
+	if ep.err = ep.Commit(ctx); ep.err != nil {
+		return false
 	}
 
 	for {
 		// Remember our current page url
-		currentPage = ep.it.Paging.Next
+		currentPage := ep.it.Paging.Next
 
 		// Attempt to get a page of events
 		var page []events.Event
 		if !ep.it.Next(ctx, &page) {
-			if ep.it.Err() == nil && len(page) == 0 {
-				// No events, sleep for our poll interval
-				goto SLEEP
+			if ep.it.Err() != nil || len(page) != 0 {
+				ep.err = ep.it.Err()
+				return false
 			}
-			ep.err = ep.it.Err()
-			return false
-		}
 
-		for _, e := range page {
-			// If any events on the page are older than our being time
-			if e.GetTimestamp().After(ep.beginTime) {
-				results = append(results, e)
+			// No new events, re-read the settling events or fetch this same page again
+			if ep.opts.SettleDelay > 0 {
+				ep.rescan()
+			} else {
+				ep.it.Paging.Next = currentPage
 			}
+
+			// Sleep the rest of our duration
+			timer := time.NewTimer(ep.opts.PollInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return false
+			case <-timer.C:
+			}
+			continue
 		}
 
-		// If we have events to return
+		// If every event on the page is a duplicate, fetch the next page right away
+		results := ep.dedupe(page)
 		if len(results) != 0 {
+			ep.pending = &EventCheckpoint{
+				Cursor:    ep.it.Paging.Next,
+				Timestamp: ep.latest,
+				Seen:      maps.Clone(ep.seen),
+			}
 			*ee = results
 			return true
 		}
+	}
+}
 
-	SLEEP:
-		// Since we didn't find an event older than our
-		// threshold, fetch this same page again
-		ep.it.Paging.Next = currentPage
+// Commit saves the checkpoint of the events returned by the last `Poll()` to ListEventOptions.Checkpoint.
+// Poll commits before fetching new events; call Commit once the last events are processed before stopping.
+func (ep *EventPoller) Commit(ctx context.Context) error {
+	if ep.pending == nil || ep.opts.Checkpoint == nil {
+		return nil
+	}
+	if err := ep.opts.Checkpoint.Save(ctx, *ep.pending); err != nil {
+		return fmt.Errorf("failed to save event checkpoint: %w", err)
+	}
+	ep.pending = nil
+	return nil
+}
 
-		// Sleep the rest of our duration
-		timer := time.NewTimer(ep.opts.PollInterval)
-		select {
-		case <-ctx.Done():
-			timer.Stop()
-			return false
-		case <-timer.C:
+// load restores the position and the seen events from the checkpoint store.
+func (ep *EventPoller) load(ctx context.Context) error {
+	ep.loaded = true
+	ep.seen = make(map[string]time.Time)
+	if ep.opts.Checkpoint == nil {
+		return nil
+	}
+
+	cp, err := ep.opts.Checkpoint.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load event checkpoint: %w", err)
+	}
+	if cp == nil {
+		return nil
+	}
+
+	ep.latest = cp.Timestamp
+	maps.Copy(ep.seen, cp.Seen)
+	switch {
+	case cp.Cursor != "":
+		ep.it.Paging.Next = cp.Cursor
+	case !cp.Timestamp.IsZero():
+		ep.rescan()
+	}
+	return nil
+}
+
+// rescan restarts the iteration from the most recent returned event, minus the settle delay.
+func (ep *EventPoller) rescan() {
+	opts := ep.opts
+	if !ep.latest.IsZero() {
+		opts.Begin = maxTime(ep.opts.Begin, ep.latest.Add(-ep.opts.SettleDelay))
+	}
+	ep.it = ep.mg.ListEvents(ep.domain, &opts)
+}
+
+// dedupe drops the events already returned, records the new ones and forgets the events outside the window.
+func (ep *EventPoller) dedupe(page []events.Event) []events.Event {
+	var results []events.Event
+	for _, e := range page {
+		ts := e.GetTimestamp()
+		if id := e.GetID(); id != "" {
+			if _, ok := ep.seen[id]; ok {
+				continue
+			}
+			ep.seen[id] = ts
 		}
+		if ts.After(ep.latest) {
+			ep.latest = ts
+		}
+		results = append(results, e)
+	}
+
+	horizon := ep.latest.Add(-ep.opts.DedupeWindow)
+	maps.DeleteFunc(ep.seen, func(_ string, ts time.Time) bool {
+		return ts.Before(horizon)
+	})
+	return results
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
 	}
+	return b
 }
@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// HandlerFunc handles an event of any type.
+type HandlerFunc func(ctx context.Context, e Event) error
+
+// Middleware wraps the handling of every event dispatched, e.g. for logging or recovery.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Dispatcher calls the handlers registered for the concrete type of each event.
+// Events from iterators, pollers and webhook payloads can be dispatched alike.
+//
+//	d := events.NewDispatcher()
+//	events.Handle(d, func(ctx context.Context, e *events.Delivered) error {
+//		fmt.Printf("delivered to %s\n", e.Recipient)
+//		return nil
+//	})
+//	events.Handle(d, func(ctx context.Context, e *events.Failed) error {
+//		fmt.Printf("failed: %s\n", e.Reason)
+//		return nil
+//	})
+//
+//	for it.Next(ctx, &page) {
+//		if err := d.DispatchAll(ctx, page); err != nil {
+//			return err
+//		}
+//	}
+//
+// A Dispatcher must be configured before it is used; dispatching is safe for concurrent use.
+type Dispatcher struct {
+	handlers   map[reflect.Type][]HandlerFunc
+	catchAll   HandlerFunc
+	onError    func(ctx context.Context, e Event, err error) error
+	middleware []Middleware
+}
+
+// NewDispatcher creates a dispatcher without handlers.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[reflect.Type][]HandlerFunc)}
+}
+
+// Handle registers a handler for the events of type T, e.g. *events.Delivered.
+// T must be the concrete type of the parsed events; several handlers of the same type run in order.
+func Handle[T Event](d *Dispatcher, fn func(ctx context.Context, e T) error) {
+	typ := reflect.TypeFor[T]()
+	d.handlers[typ] = append(d.handlers[typ], func(ctx context.Context, e Event) error {
+		//nolint:revive // unchecked-type-assertion: handlers are looked up by the event type.
+		return fn(ctx, e.(T))
+	})
+}
+
+// HandleAny registers the handler of the events without a handler for their type,
+// including *events.Unknown events of new or unregistered types.
+func (d *Dispatcher) HandleAny(fn HandlerFunc) {
+	d.catchAll = fn
+}
+
+// OnError registers the function called with the errors returned by the handlers.
+// Its result is returned by Dispatch; return nil to ignore the error and continue dispatching.
+func (d *Dispatcher) OnError(fn func(ctx context.Context, e Event, err error) error) {
+	d.onError = fn
+}
+
+// Use adds middleware wrapping the handling of every event. The first middleware added is the outermost.
+func (d *Dispatcher) Use(mw ...Middleware) {
+	d.middleware = append(d.middleware, mw...)
+}
+
+// Dispatch calls the handlers of the event. Events without a handler are ignored
+// unless a catch-all handler is registered with HandleAny.
+func (d *Dispatcher) Dispatch(ctx context.Context, e Event) error {
+	h := HandlerFunc(d.handle)
+	for i := len(d.middleware) - 1; i >= 0; i-- {
+		h = d.middleware[i](h)
+	}
+
+	err := h(ctx, e)
+	if err != nil && d.onError != nil {
+		return d.onError(ctx, e, err)
+	}
+	return err
+}
+
+// DispatchAll dispatches the events in order, e.g. a page returned by an iterator or a poller.
+// It stops at the first error.
+func (d *Dispatcher) DispatchAll(ctx context.Context, ee []Event) error {
+	for _, e := range ee {
+		if err := d.Dispatch(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DispatchJSON parses and dispatches a raw event, e.g. the `event-data` of a webhook payload.
+func (d *Dispatcher) DispatchJSON(ctx context.Context, raw []byte) error {
+	e, err := ParseEvent(raw)
+	if err != nil {
+		return fmt.Errorf("while dispatching event: %w", err)
+	}
+	return d.Dispatch(ctx, e)
+}
+
+func (d *Dispatcher) handle(ctx context.Context, e Event) error {
+	handlers, ok := d.handlers[reflect.TypeOf(e)]
+	if !ok {
+		if d.catchAll == nil {
+			return nil
+		}
+		return d.catchAll(ctx, e)
+	}
+
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	var calls []string
+
+	d := NewDispatcher()
+	Handle(d, func(_ context.Context, e *Delivered) error {
+		calls = append(calls, "delivered:"+e.Recipient)
+		return nil
+	})
+	Handle(d, func(_ context.Context, _ *Delivered) error {
+		calls = append(calls, "delivered-2")
+		return nil
+	})
+	Handle(d, func(_ context.Context, e *Failed) error {
+		calls = append(calls, "failed:"+e.Severity)
+		return nil
+	})
+	d.HandleAny(func(_ context.Context, e Event) error {
+		calls = append(calls, "any:"+e.GetName())
+		return nil
+	})
+	d.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, e Event) error {
+			calls = append(calls, "mw")
+			return next(ctx, e)
+		}
+	})
+
+	delivered := &Delivered{Recipient: "joe@example.com"}
+	failed := &Failed{Severity: SeverityPermanent}
+	require.NoError(t, d.DispatchAll(ctx, []Event{delivered, failed}))
+	require.NoError(t, d.DispatchJSON(ctx, []byte(`{"event": "brand_new_event"}`)))
+
+	assert.Equal(t, []string{
+		"mw", "delivered:joe@example.com", "delivered-2",
+		"mw", "failed:permanent",
+		"mw", "any:brand_new_event",
+	}, calls)
+}
+
+func TestDispatcherErrors(t *testing.T) {
+	ctx := context.Background()
+	errHandler := errors.New("handler error")
+	var handled int
+
+	d := NewDispatcher()
+	Handle(d, func(_ context.Context, _ *Opened) error {
+		handled++
+		return errHandler
+	})
+
+	err := d.DispatchAll(ctx, []Event{&Opened{}, &Opened{}})
+	require.ErrorIs(t, err, errHandler)
+	assert.Equal(t, 1, handled)
+
+	// Events without handlers are ignored.
+	require.NoError(t, d.Dispatch(ctx, &Clicked{}))
+
+	var failed []Event
+	d.OnError(func(_ context.Context, e Event, _ error) error {
+		failed = append(failed, e)
+		return nil
+	})
+	require.NoError(t, d.DispatchAll(ctx, []Event{&Opened{}, &Opened{}}))
+	assert.Len(t, failed, 2)
+
+	require.Error(t, d.DispatchJSON(ctx, []byte(`not json`)))
+}
@@ -54,4 +54,7 @@ const (
 	MethodUnknown = "unknown"
 	MethodSMTP    = "smtp"
 	MethodHTTP    = "http"
+
+	BounceTypeHard = "hard"
+	BounceTypeSoft = "soft"
 )
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"encoding/json"
 	"strings"
 	"time"
 )
@@ -62,15 +63,21 @@ type Accepted struct {
 	Campaigns       []Campaign `json:"campaigns"`
 	UserVariables   any        `json:"user-variables"`
 	Storage         Storage    `json:"storage"`
+
+	// Routes are the routes matched by an incoming message, see IsIncoming.
+	Routes []Route `json:"routes,omitempty"`
+}
+
+// IsIncoming reports whether the event is about an incoming message accepted by routes,
+// rather than an outgoing message accepted for delivery.
+func (a *Accepted) IsIncoming() bool {
+	return a.Flags.IsRouted || len(a.Routes) > 0
 }
 
 type Rejected struct {
 	Generic
 
-	Reject struct {
-		Reason      string `json:"reason"`
-		Description string `json:"description"`
-	} `json:"reject"`
+	Reject Reject `json:"reject"`
 
 	Message Message `json:"message"`
 	Storage Storage `json:"storage"`
@@ -131,6 +138,30 @@ type Failed struct {
 	UserVariables any    `json:"user-variables"`
 }
 
+// Dropped - Mailgun dropped the message without attempting a delivery,
+// e.g. because the recipient is suppressed or previously bounced.
+type Dropped struct {
+	Generic
+
+	Envelope Envelope `json:"envelope"`
+	Message  Message  `json:"message"`
+	Flags    Flags    `json:"flags"`
+
+	Recipient       string     `json:"recipient"`
+	RecipientDomain string     `json:"recipient-domain"`
+	Method          string     `json:"method"`
+	Tags            []string   `json:"tags"`
+	Campaigns       []Campaign `json:"campaigns"`
+	Storage         Storage    `json:"storage"`
+
+	DeliveryStatus DeliveryStatus `json:"delivery-status"`
+
+	Severity      string `json:"severity"`
+	Reason        string `json:"reason"`
+	Description   string `json:"description"`
+	UserVariables any    `json:"user-variables"`
+}
+
 type Stored struct {
 	Generic
 
@@ -219,14 +250,14 @@ type Complained struct {
 //
 
 type MailingListMember struct {
-	Subscribed bool
-	Address    string
-	Name       string
-	Vars       []string
+	Subscribed bool     `json:"subscribed"`
+	Address    string   `json:"address"`
+	Name       string   `json:"name"`
+	Vars       []string `json:"vars"`
 }
 
 type MailingListError struct {
-	Message string
+	Message string `json:"message"`
 }
 
 type ListMemberUploaded struct {
@@ -257,6 +288,27 @@ type ListUploaded struct {
 	TaskID        string            `json:"task-id"`
 }
 
+// Unknown is an event whose name is not registered, see RegisterEvent.
+// It keeps the common fields and the raw JSON of the event, so new event types
+// returned by Mailgun do not break parsing.
+type Unknown struct {
+	Generic
+	Raw RawJSON `json:"-"`
+}
+
+// MarshalJSON returns the raw JSON of the event.
+func (u *Unknown) MarshalJSON() ([]byte, error) {
+	if u.Raw == nil {
+		return json.Marshal(u.Generic)
+	}
+	return u.Raw, nil
+}
+
+// Decode unmarshals the raw JSON of the event into v, e.g. a custom event struct.
+func (u *Unknown) Decode(v any) error {
+	return json.Unmarshal(u.Raw, v)
+}
+
 type Paging struct {
 	First    string `json:"first,omitempty"`
 	Next     string `json:"next,omitempty"`
@@ -271,6 +323,13 @@ func (v *RawJSON) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (v RawJSON) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
 type Response struct {
 	Items  []RawJSON `json:"items"`
 	Paging Paging    `json:"paging"`
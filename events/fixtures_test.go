@@ -0,0 +1,177 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadFixture parses the event of testdata/<name>.json.
+func loadFixture(t *testing.T, name string) (Event, []byte) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	require.NoError(t, err)
+	event, err := ParseEvent(data)
+	require.NoError(t, err)
+	return event, data
+}
+
+// TestEventFixturesRoundTrip makes sure every field of the fixtures survives an unmarshal and marshal,
+// so a field missing from the event structs fails the test instead of being silently dropped.
+func TestEventFixturesRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			event, data := loadFixture(t, name)
+
+			var fixture map[string]any
+			require.NoError(t, json.Unmarshal(data, &fixture))
+			want, ok := NewEvent(fixture["event"].(string))
+			require.True(t, ok, "event '%s' is not registered", fixture["event"])
+			require.Equal(t, reflect.TypeOf(want), reflect.TypeOf(event))
+
+			out, err := json.Marshal(event)
+			require.NoError(t, err)
+			var roundTrip map[string]any
+			require.NoError(t, json.Unmarshal(out, &roundTrip))
+
+			for _, diff := range jsonSubsetDiff("", fixture, roundTrip) {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestEventFixturesCoverEveryEvent(t *testing.T) {
+	covered := make(map[string]bool)
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	require.NoError(t, err)
+	for _, path := range paths {
+		event, _ := loadFixture(t, strings.TrimSuffix(filepath.Base(path), ".json"))
+		covered[event.GetName()] = true
+	}
+
+	for _, name := range RegisteredEvents() {
+		assert.True(t, covered[name], "no fixture for event '%s'", name)
+	}
+}
+
+// jsonSubsetDiff returns the differences of the values of want missing or different in got.
+// A null in want matches any zero value, as Go has no null for scalars.
+func jsonSubsetDiff(path string, want, got any) []string {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: want an object, got %v", path, got)}
+		}
+		var diffs []string
+		for k, v := range w {
+			child, ok := g[k]
+			if !ok {
+				// Empty values can be omitted.
+				if !isZeroJSON(v) {
+					diffs = append(diffs, fmt.Sprintf("%s.%s: dropped", path, k))
+				}
+				continue
+			}
+			diffs = append(diffs, jsonSubsetDiff(path+"."+k, v, child)...)
+		}
+		return diffs
+	case []any:
+		g, ok := got.([]any)
+		if !ok && len(w) == 0 && got == nil {
+			return nil
+		}
+		if !ok || len(g) != len(w) {
+			return []string{fmt.Sprintf("%s: want %v, got %v", path, w, got)}
+		}
+		var diffs []string
+		for i := range w {
+			diffs = append(diffs, jsonSubsetDiff(fmt.Sprintf("%s[%d]", path, i), w[i], g[i])...)
+		}
+		return diffs
+	case nil:
+		if !isZeroJSON(got) {
+			return []string{fmt.Sprintf("%s: want null, got %v", path, got)}
+		}
+		return nil
+	default:
+		if !reflect.DeepEqual(want, got) {
+			return []string{fmt.Sprintf("%s: want %v, got %v", path, want, got)}
+		}
+		return nil
+	}
+}
+
+func isZeroJSON(v any) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
+
+func TestParseAcceptedIncoming(t *testing.T) {
+	event, _ := loadFixture(t, "accepted_incoming")
+	accepted := event.(*Accepted)
+
+	assert.True(t, accepted.IsIncoming())
+	assert.Equal(t, MethodSMTP, accepted.Method)
+	assert.Equal(t, "support@example.com", accepted.Envelope.Targets)
+	require.Len(t, accepted.Routes, 1)
+	assert.Equal(t, `match_recipient("support@example.com")`, accepted.Routes[0].Expression)
+	assert.Equal(t, "support@example.com", accepted.Routes[0].Match["recipient"])
+
+	event, _ = loadFixture(t, "accepted")
+	assert.False(t, event.(*Accepted).IsIncoming())
+}
+
+func TestParseDropped(t *testing.T) {
+	event, _ := loadFixture(t, "dropped")
+	dropped := event.(*Dropped)
+
+	assert.Equal(t, ReasonSuppressBounce, dropped.Reason)
+	assert.Equal(t, SeverityPermanent, dropped.Severity)
+	assert.Equal(t, "FgEASDSGFB8y4--TSDGxvccvmQB==", dropped.Storage.Key)
+	assert.True(t, dropped.DeliveryStatus.IsHardBounce())
+}
+
+func TestParseRejected(t *testing.T) {
+	event, _ := loadFixture(t, "rejected")
+	rejected := event.(*Rejected)
+
+	assert.Equal(t, "Sandbox subdomains are for test purposes only.", rejected.Reject.Reason)
+	assert.Equal(t, "CgEASDSGFB8y4--TSDGxvccvmQB==", rejected.Storage.Key)
+}
+
+func TestDeliveryStatusBounceType(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		status     DeliveryStatus
+		hard, soft bool
+	}{
+		{name: "hard", status: DeliveryStatus{Code: 451, BounceType: BounceTypeHard}, hard: true},
+		{name: "soft", status: DeliveryStatus{Code: 550, BounceType: BounceTypeSoft}, soft: true},
+		{name: "5xx", status: DeliveryStatus{Code: 550}, hard: true},
+		{name: "4xx", status: DeliveryStatus{Code: 421}, soft: true},
+		{name: "delivered", status: DeliveryStatus{Code: 250}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.hard, tc.status.IsHardBounce())
+			assert.Equal(t, tc.soft, tc.status.IsSoftBounce())
+		})
+	}
+
+	event, _ := loadFixture(t, "failed")
+	assert.True(t, event.(*Failed).DeliveryStatus.IsHardBounce())
+	event, _ = loadFixture(t, "failed_temporary")
+	assert.True(t, event.(*Failed).DeliveryStatus.IsSoftBounce())
+}
@@ -0,0 +1,240 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the legacy webhook events that differ from the event names.
+const (
+	LegacyEventBounced = "bounced"
+	LegacyEventSpam    = "spam"
+)
+
+// legacyFields are the form fields of legacy webhooks that are not user variables.
+var legacyFields = map[string]bool{
+	"event": true, "recipient": true, "domain": true, "message-headers": true, "message-id": true,
+	"timestamp": true, "token": true, "signature": true, "reason": true, "code": true, "error": true,
+	"description": true, "notification": true, "ip": true, "country": true, "region": true, "city": true,
+	"user-agent": true, "device-type": true, "client-type": true, "client-name": true, "client-os": true,
+	"campaign-id": true, "campaign-name": true, "tag": true, "x-mailgun-tag": true, "mailing-list": true,
+	"url": true, "attachment-count": true, "body-plain": true,
+}
+
+// ParseLegacyEvent converts the form of a legacy webhook, sent by domains that still use the
+// form-encoded webhooks, into the event struct of the same event in the JSON format:
+// "bounced" webhooks become *Failed events, "dropped" *Dropped events, "spam" *Complained events,
+// and "delivered", "opened", "clicked", "unsubscribed" and "complained" their event struct.
+//
+// Legacy webhooks have no event ID, and the extra form fields are returned as user variables.
+func ParseLegacyEvent(form url.Values) (Event, error) {
+	name := strings.ToLower(form.Get("event"))
+	if name == "" {
+		return nil, fmt.Errorf("failed to recognize legacy event: no event field")
+	}
+
+	message, err := legacyMessage(form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse legacy event '%s': %w", name, err)
+	}
+	recipient := form.Get("recipient")
+	_, recipientDomain, _ := strings.Cut(recipient, "@")
+	tags := slices.Concat(form["tag"], formValues(form, "X-Mailgun-Tag"))
+	userVariables := legacyUserVariables(form)
+	var campaigns []Campaign
+	if id := form.Get("campaign-id"); id != "" {
+		campaigns = []Campaign{{ID: id, Name: form.Get("campaign-name")}}
+	}
+	code, _ := strconv.Atoi(form.Get("code"))
+
+	var e Event
+	switch name {
+	case EventDelivered:
+		e = &Delivered{
+			Message:         message,
+			Recipient:       recipient,
+			RecipientDomain: recipientDomain,
+			Tags:            tags,
+			Campaigns:       campaigns,
+			UserVariables:   userVariables,
+		}
+	case LegacyEventBounced:
+		name = EventFailed
+		e = &Failed{
+			Message:         message,
+			Recipient:       recipient,
+			RecipientDomain: recipientDomain,
+			Tags:            tags,
+			Campaigns:       campaigns,
+			DeliveryStatus: DeliveryStatus{
+				Code:        code,
+				Message:     form.Get("error"),
+				Description: form.Get("notification"),
+				BounceType:  BounceTypeHard,
+			},
+			Severity:      SeverityPermanent,
+			Reason:        ReasonBounce,
+			UserVariables: userVariables,
+		}
+	case EventDropped:
+		e = &Dropped{
+			Message:         message,
+			Recipient:       recipient,
+			RecipientDomain: recipientDomain,
+			Tags:            tags,
+			Campaigns:       campaigns,
+			DeliveryStatus:  DeliveryStatus{Code: code, Description: form.Get("description")},
+			Severity:        SeverityPermanent,
+			Reason:          form.Get("reason"),
+			Description:     form.Get("description"),
+			UserVariables:   userVariables,
+		}
+	case EventComplained, LegacyEventSpam:
+		name = EventComplained
+		e = &Complained{
+			Message:       message,
+			Recipient:     recipient,
+			Tags:          tags,
+			Campaigns:     campaigns,
+			UserVariables: userVariables,
+		}
+	case EventOpened:
+		e = &Opened{
+			Message:         message,
+			Campaigns:       campaigns,
+			MailingList:     MailingList{Address: form.Get("mailing-list")},
+			Recipient:       recipient,
+			RecipientDomain: recipientDomain,
+			Tags:            tags,
+			IP:              form.Get("ip"),
+			ClientInfo:      legacyClientInfo(form),
+			GeoLocation:     legacyGeoLocation(form),
+			UserVariables:   userVariables,
+		}
+	case EventClicked:
+		e = &Clicked{
+			Url:             form.Get("url"),
+			Message:         message,
+			Campaigns:       campaigns,
+			MailingList:     MailingList{Address: form.Get("mailing-list")},
+			Recipient:       recipient,
+			RecipientDomain: recipientDomain,
+			Tags:            tags,
+			IP:              form.Get("ip"),
+			ClientInfo:      legacyClientInfo(form),
+			GeoLocation:     legacyGeoLocation(form),
+			UserVariables:   userVariables,
+		}
+	case EventUnsubscribed:
+		e = &Unsubscribed{
+			Message:         message,
+			Campaigns:       campaigns,
+			MailingList:     MailingList{Address: form.Get("mailing-list")},
+			Recipient:       recipient,
+			RecipientDomain: recipientDomain,
+			Tags:            tags,
+			IP:              form.Get("ip"),
+			ClientInfo:      legacyClientInfo(form),
+			GeoLocation:     legacyGeoLocation(form),
+			UserVariables:   userVariables,
+		}
+	default:
+		return nil, fmt.Errorf("failed to recognize legacy event '%s'", name)
+	}
+
+	e.SetName(name)
+	if ts := form.Get("timestamp"); ts != "" {
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse legacy event '%s': invalid timestamp '%s'", name, ts)
+		}
+		e.SetTimestamp(time.Unix(sec, 0))
+	}
+	return e, nil
+}
+
+// legacyMessage returns the message of the message-headers and Message-Id fields.
+func legacyMessage(form url.Values) (Message, error) {
+	var m Message
+	if s := form.Get("message-headers"); s != "" {
+		var headers [][]string
+		if err := json.Unmarshal([]byte(s), &headers); err != nil {
+			return m, fmt.Errorf("invalid message-headers: %w", err)
+		}
+		for _, h := range headers {
+			if len(h) < 2 {
+				continue
+			}
+			switch strings.ToLower(h[0]) {
+			case "to":
+				m.Headers.To = h[1]
+			case "from":
+				m.Headers.From = h[1]
+			case "subject":
+				m.Headers.Subject = h[1]
+			case "message-id":
+				m.Headers.MessageID = h[1]
+			}
+		}
+	}
+	if ids := formValues(form, "Message-Id"); len(ids) != 0 && ids[0] != "" {
+		m.Headers.MessageID = ids[0]
+	}
+	m.Headers.MessageID = strings.TrimSuffix(strings.TrimPrefix(m.Headers.MessageID, "<"), ">")
+	return m, nil
+}
+
+func legacyClientInfo(form url.Values) ClientInfo {
+	return ClientInfo{
+		ClientName: form.Get("client-name"),
+		ClientOS:   form.Get("client-os"),
+		ClientType: form.Get("client-type"),
+		DeviceType: form.Get("device-type"),
+		IP:         form.Get("ip"),
+		UserAgent:  form.Get("user-agent"),
+	}
+}
+
+func legacyGeoLocation(form url.Values) GeoLocation {
+	return GeoLocation{
+		City:    form.Get("city"),
+		Country: form.Get("country"),
+		Region:  form.Get("region"),
+	}
+}
+
+// legacyUserVariables returns the form fields that are not legacy webhook fields, i.e. the custom variables
+// of the message. Variables with several values are returned as a []string.
+// formValues returns the values of the form field, case-insensitively, for the fields named after
+// a message header, e.g. Message-Id, whose case depends on the sender of the message.
+func formValues(form url.Values, name string) []string {
+	var values []string
+	for _, key := range slices.Sorted(maps.Keys(form)) {
+		if strings.EqualFold(key, name) {
+			values = append(values, form[key]...)
+		}
+	}
+	return values
+}
+
+func legacyUserVariables(form url.Values) map[string]any {
+	vars := map[string]any{}
+	for key, values := range form {
+		if legacyFields[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "attachment-") ||
+			len(values) == 0 {
+			continue
+		}
+		if len(values) == 1 {
+			vars[key] = values[0]
+		} else {
+			vars[key] = values
+		}
+	}
+	return vars
+}
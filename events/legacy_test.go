@@ -0,0 +1,95 @@
+package events
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLegacyEvent(t *testing.T) {
+	form := url.Values{
+		"event":           {"bounced"},
+		"recipient":       {"alice@example.com"},
+		"domain":          {"mg.example.com"},
+		"message-headers": {`[["To", "Alice <alice@example.com>"], ["Subject", "Hello"], ["Message-Id", "<header@example.com>"]]`},
+		"Message-Id":      {"<20130503182626.18666.16540@mg.example.com>"},
+		"code":            {"550"},
+		"error":           {"5.1.1 The email account that you tried to reach does not exist."},
+		"notification":    {"No such user"},
+		"tag":             {"newsletter", "weekly"},
+		"timestamp":       {"1533922516"},
+		"token":           {"token"},
+		"signature":       {"signature"},
+		"account-id":      {"42"},
+		"attachment-1":    {"ignored"},
+	}
+
+	e, err := ParseLegacyEvent(form)
+	require.NoError(t, err)
+	failed, ok := e.(*Failed)
+	require.True(t, ok)
+	assert.Equal(t, EventFailed, failed.GetName())
+	assert.Equal(t, int64(1533922516), failed.GetTimestamp().Unix())
+	assert.Equal(t, "alice@example.com", failed.Recipient)
+	assert.Equal(t, "example.com", failed.RecipientDomain)
+	assert.Equal(t, "20130503182626.18666.16540@mg.example.com", failed.Message.Headers.MessageID)
+	assert.Equal(t, "Hello", failed.Message.Headers.Subject)
+	assert.Equal(t, []string{"newsletter", "weekly"}, failed.Tags)
+	assert.Equal(t, SeverityPermanent, failed.Severity)
+	assert.Equal(t, 550, failed.DeliveryStatus.Code)
+	assert.True(t, failed.DeliveryStatus.IsHardBounce())
+	assert.Equal(t, map[string]any{"account-id": "42"}, failed.UserVariables)
+}
+
+func TestParseLegacyEventNames(t *testing.T) {
+	for legacy, want := range map[string]Event{
+		"delivered":    &Delivered{},
+		"dropped":      &Dropped{},
+		"spam":         &Complained{},
+		"complained":   &Complained{},
+		"opened":       &Opened{},
+		"clicked":      &Clicked{},
+		"unsubscribed": &Unsubscribed{},
+	} {
+		t.Run(legacy, func(t *testing.T) {
+			e, err := ParseLegacyEvent(url.Values{"event": {legacy}, "timestamp": {"1533922516"}})
+			require.NoError(t, err)
+			assert.IsType(t, want, e)
+		})
+	}
+
+	e, err := ParseLegacyEvent(url.Values{
+		"event": {"clicked"}, "url": {"https://example.com"}, "ip": {"198.51.100.20"},
+		"country": {"US"}, "client-name": {"Firefox"}, "campaign-id": {"c1"},
+	})
+	require.NoError(t, err)
+	clicked := e.(*Clicked)
+	assert.Equal(t, "https://example.com", clicked.Url)
+	assert.Equal(t, "198.51.100.20", clicked.IP)
+	assert.Equal(t, "US", clicked.GeoLocation.Country)
+	assert.Equal(t, "Firefox", clicked.ClientInfo.ClientName)
+	assert.Equal(t, []Campaign{{ID: "c1"}}, clicked.Campaigns)
+
+	// The header fields are matched case-insensitively.
+	e, err = ParseLegacyEvent(url.Values{
+		"event": {"delivered"}, "x-mailgun-tag": {"receipt"}, "Message-ID": {"<id@example.com>"},
+	})
+	require.NoError(t, err)
+	delivered := e.(*Delivered)
+	assert.Equal(t, []string{"receipt"}, delivered.Tags)
+	assert.Equal(t, "id@example.com", delivered.Message.Headers.MessageID)
+
+	_, err = ParseLegacyEvent(url.Values{"event": {"delivered"}, "message-headers": {"not json"}})
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+
+	_, err = ParseLegacyEvent(url.Values{"event": {"teleported"}})
+	assert.Error(t, err)
+	_, err = ParseLegacyEvent(url.Values{"recipient": {"alice@example.com"}})
+	assert.Error(t, err)
+	_, err = ParseLegacyEvent(url.Values{"event": {"delivered"}, "timestamp": {"now"}})
+	assert.Error(t, err)
+}
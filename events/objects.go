@@ -52,6 +52,25 @@ type Flags struct {
 	IsSystemTest    bool `json:"is-system-test"`
 	IsTestMode      bool `json:"is-test-mode"`
 	IsDelayedBounce bool `json:"is-delayed-bounce"`
+	IsRouted        bool `json:"is-routed"`
+	IsCallback      bool `json:"is-callback"`
+	IsEncrypted     bool `json:"is-encrypted"`
+}
+
+// Route is a route matched by an incoming message.
+type Route struct {
+	ID          string            `json:"id"`
+	Priority    int               `json:"priority"`
+	Expression  string            `json:"expression"`
+	Description string            `json:"description"`
+	Actions     []string          `json:"actions,omitempty"`
+	Match       map[string]string `json:"match,omitempty"`
+}
+
+// Reject is the reason of a rejected message.
+type Reject struct {
+	Reason      string `json:"reason"`
+	Description string `json:"description"`
 }
 
 type Attachment struct {
@@ -89,3 +108,26 @@ type DeliveryStatus struct {
 	BounceType                  string  `json:"bounce-type,omitempty"`
 	FirstDeliveryAttemptSeconds float64 `json:"first-delivery-attempt-seconds,omitempty"`
 }
+
+// IsHardBounce reports whether the delivery failed permanently. The bounce type is used when
+// the server reported it, otherwise the failure is classified by its SMTP code.
+func (ds *DeliveryStatus) IsHardBounce() bool {
+	switch ds.BounceType {
+	case BounceTypeHard:
+		return true
+	case BounceTypeSoft:
+		return false
+	}
+	return ds.Code >= 500 && ds.Code < 600
+}
+
+// IsSoftBounce reports whether the delivery failed temporarily, see IsHardBounce.
+func (ds *DeliveryStatus) IsSoftBounce() bool {
+	switch ds.BounceType {
+	case BounceTypeSoft:
+		return true
+	case BounceTypeHard:
+		return false
+	}
+	return ds.Code >= 400 && ds.Code < 500
+}
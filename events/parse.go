@@ -3,7 +3,11 @@ package events
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,12 +21,15 @@ type Event interface {
 	SetID(id string)
 }
 
-// EventNames - a list of all JSON event types returned by the /events API
+// EventNames - a list of all JSON event types returned by the /events API.
+// It is a read-only list of the built-in events: it is not updated by RegisterEvent
+// and UnregisterEvent, use RegisteredEvents and NewEvent to look up the registered events.
 var EventNames = map[string]func() Event{
 	"accepted":                 new_(Accepted{}),
 	"clicked":                  new_(Clicked{}),
 	"complained":               new_(Complained{}),
 	"delivered":                new_(Delivered{}),
+	"dropped":                  new_(Dropped{}),
 	"failed":                   new_(Failed{}),
 	"opened":                   new_(Opened{}),
 	"rejected":                 new_(Rejected{}),
@@ -33,6 +40,56 @@ var EventNames = map[string]func() Event{
 	"list_uploaded":            new_(ListUploaded{}),
 }
 
+var (
+	// registry maps the registered event names to their constructors, guarded by registryMutex.
+	registry      = maps.Clone(EventNames)
+	registryMutex sync.RWMutex
+)
+
+// RegisterEvent registers the constructor of the event struct to use for the event name,
+// replacing any previously registered constructor. It is safe for concurrent use with ParseEvent.
+//
+//	events.RegisterEvent("my_event", func() events.Event { return new(MyEvent) })
+func RegisterEvent(name string, newEvent func() Event) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	registry[strings.ToLower(name)] = newEvent
+}
+
+// UnregisterEvent removes the event name from the registry, the event is parsed as *Unknown afterward.
+func UnregisterEvent(name string) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	delete(registry, strings.ToLower(name))
+}
+
+// IsRegisteredEvent reports whether the event name has a registered event struct.
+func IsRegisteredEvent(name string) bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	_, ok := registry[strings.ToLower(name)]
+	return ok
+}
+
+// NewEvent returns a new event struct registered for the event name,
+// or false if the name is not registered.
+func NewEvent(name string) (Event, bool) {
+	registryMutex.RLock()
+	newEvent, ok := registry[strings.ToLower(name)]
+	registryMutex.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return newEvent(), true
+}
+
+// RegisteredEvents returns the sorted names of the registered events.
+func RegisteredEvents() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	return slices.Sorted(maps.Keys(registry))
+}
+
 // new_ is a universal event "constructor".
 func new_(e any) func() Event {
 	typ := reflect.TypeOf(e)
@@ -72,7 +129,8 @@ func ParseEvents(raw []RawJSON) ([]Event, error) {
 	return result, nil
 }
 
-// ParseEvent converts raw bytes data into an event struct. Can accept events.RawJSON as input
+// ParseEvent converts raw bytes data into an event struct. Can accept events.RawJSON as input.
+// Events with an unregistered name are returned as *Unknown.
 func ParseEvent(raw []byte) (Event, error) {
 	// Try to recognize the event first.
 	var e EventName
@@ -80,10 +138,12 @@ func ParseEvent(raw []byte) (Event, error) {
 		return nil, fmt.Errorf("failed to recognize event: %v", err)
 	}
 
-	// Get the event "constructor" from the map.
-	newEvent, ok := EventNames[e.GetName()]
+	// Get the event "constructor" from the registry.
+	registryMutex.RLock()
+	newEvent, ok := registry[e.GetName()]
+	registryMutex.RUnlock()
 	if !ok {
-		return nil, fmt.Errorf("unsupported event: '%s'", e.GetName())
+		return parseUnknown(e, raw), nil
 	}
 	event := newEvent()
 
@@ -94,3 +154,13 @@ func ParseEvent(raw []byte) (Event, error) {
 
 	return event, nil
 }
+
+// parseUnknown never fails: if the common fields of a new event type have an unexpected
+// format, only the name is kept and the fields can still be decoded from Raw.
+func parseUnknown(name EventName, raw []byte) *Unknown {
+	event := &Unknown{Raw: slices.Clone(raw)}
+	if err := json.Unmarshal(raw, &event.Generic); err != nil {
+		event.Generic = Generic{EventName: name}
+	}
+	return event
+}
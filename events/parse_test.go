@@ -1,6 +1,7 @@
 package events
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
@@ -16,9 +17,6 @@ func TestParseErrors(t *testing.T) {
 	// TODO(vtopc): do not compare strings, use errors.Is or errors.As:
 	require.Contains(t, err.Error(), "failed to recognize event")
 
-	_, err = ParseEvent([]byte(`{"event": "unknown_event"}`))
-	require.EqualError(t, err, "unsupported event: 'unknown_event'")
-
 	_, err = ParseEvent([]byte(`{
 		"event": "accepted",
 		"timestamp": "1420255392.850187"
@@ -161,13 +159,72 @@ func TestTimeStamp(t *testing.T) {
 }
 
 func TestEventNames(t *testing.T) {
-	for name := range EventNames {
+	for _, name := range RegisteredEvents() {
 		event, err := ParseEvent([]byte(fmt.Sprintf(`{"event": "%s"}`, name)))
 		require.NoError(t, err)
 		assert.Equal(t, name, event.GetName())
 	}
 }
 
+func TestParseUnknown(t *testing.T) {
+	raw := []byte(`{"event": "New_Event", "id": "abc", "timestamp": 1546899001.019501, "custom": {"a": 1}}`)
+	event, err := ParseEvent(raw)
+	require.NoError(t, err)
+
+	unknown, ok := event.(*Unknown)
+	require.True(t, ok)
+	assert.Equal(t, "new_event", unknown.GetName())
+	assert.Equal(t, "abc", unknown.GetID())
+	assert.Equal(t, time.Date(2019, 1, 7, 22, 10, 1, 19501056, time.UTC), unknown.GetTimestamp())
+
+	var custom struct {
+		Custom struct {
+			A int `json:"a"`
+		} `json:"custom"`
+	}
+	require.NoError(t, unknown.Decode(&custom))
+	assert.Equal(t, 1, custom.Custom.A)
+
+	b, err := json.Marshal(unknown)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(raw), string(b))
+
+	// Unexpected common fields of a new event type do not fail parsing.
+	event, err = ParseEvent([]byte(`{"event": "new_event", "timestamp": "yesterday"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "new_event", event.GetName())
+}
+
+type customEvent struct {
+	Generic
+	Custom string `json:"custom"`
+}
+
+func TestRegisterEvent(t *testing.T) {
+	RegisterEvent("Custom_Event", func() Event { return new(customEvent) })
+	t.Cleanup(func() { UnregisterEvent("custom_event") })
+
+	assert.True(t, IsRegisteredEvent("custom_event"))
+	assert.Contains(t, RegisteredEvents(), "custom_event")
+	assert.NotContains(t, EventNames, "custom_event")
+	event, ok := NewEvent("Custom_Event")
+	require.True(t, ok)
+	assert.IsType(t, &customEvent{}, event)
+
+	event, err := ParseEvent([]byte(`{"event": "custom_event", "custom": "value"}`))
+	require.NoError(t, err)
+	require.IsType(t, &customEvent{}, event)
+	assert.Equal(t, "value", event.(*customEvent).Custom)
+
+	UnregisterEvent("custom_event")
+	assert.False(t, IsRegisteredEvent("custom_event"))
+	_, ok = NewEvent("custom_event")
+	assert.False(t, ok)
+	event, err = ParseEvent([]byte(`{"event": "custom_event", "custom": "value"}`))
+	require.NoError(t, err)
+	assert.IsType(t, &Unknown{}, event)
+}
+
 func TestEventMessageWithAttachment(t *testing.T) {
 	body := []byte(`{
         "event": "delivered",
@@ -0,0 +1,127 @@
+package mailgun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// EventCheckpoint is the position of an EventPoller, saved after the events
+// returned by `Poll()` have been processed so that polling can resume after a restart.
+type EventCheckpoint struct {
+	// Cursor is the URL of the next page of events to fetch.
+	Cursor string `json:"cursor,omitempty"`
+	// Timestamp is the timestamp of the most recent event returned by the poller.
+	Timestamp time.Time `json:"timestamp"`
+	// Seen holds the IDs and timestamps of the events returned within the de-duplication window.
+	Seen map[string]time.Time `json:"seen,omitempty"`
+}
+
+// EventCheckpointStore persists the checkpoint of an EventPoller, see ListEventOptions.Checkpoint.
+type EventCheckpointStore interface {
+	// Load returns the last saved checkpoint, or nil if there is none.
+	Load(ctx context.Context) (*EventCheckpoint, error)
+	// Save stores the checkpoint, replacing the previous one.
+	Save(ctx context.Context, cp EventCheckpoint) error
+}
+
+// MemoryEventCheckpointStore keeps the checkpoint in memory, e.g. to share
+// the position between several pollers of the same process.
+type MemoryEventCheckpointStore struct {
+	mutex sync.Mutex
+	cp    *EventCheckpoint
+}
+
+// NewMemoryEventCheckpointStore creates an empty in-memory checkpoint store.
+func NewMemoryEventCheckpointStore() *MemoryEventCheckpointStore {
+	return &MemoryEventCheckpointStore{}
+}
+
+func (s *MemoryEventCheckpointStore) Load(_ context.Context) (*EventCheckpoint, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.cp == nil {
+		return nil, nil
+	}
+	cp := *s.cp
+	cp.Seen = maps.Clone(s.cp.Seen)
+	return &cp, nil
+}
+
+func (s *MemoryEventCheckpointStore) Save(_ context.Context, cp EventCheckpoint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	cp.Seen = maps.Clone(cp.Seen)
+	s.cp = &cp
+	return nil
+}
+
+// FileEventCheckpointStore keeps the checkpoint in a JSON file.
+// The file is replaced atomically, so a crash never leaves a partially written checkpoint.
+type FileEventCheckpointStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileEventCheckpointStore creates a checkpoint store saving to the file at path.
+// The file is created on the first save.
+func NewFileEventCheckpointStore(path string) *FileEventCheckpointStore {
+	return &FileEventCheckpointStore{path: path}
+}
+
+func (s *FileEventCheckpointStore) Load(_ context.Context) (*EventCheckpoint, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("while reading event checkpoint: %w", err)
+	}
+
+	var cp EventCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("while decoding event checkpoint '%s': %w", s.path, err)
+	}
+	return &cp, nil
+}
+
+func (s *FileEventCheckpointStore) Save(_ context.Context, cp EventCheckpoint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("while encoding event checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("while saving event checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("while saving event checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("while saving event checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("while saving event checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("while saving event checkpoint: %w", err)
+	}
+	return nil
+}
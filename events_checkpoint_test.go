@@ -0,0 +1,169 @@
+package mailgun_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventsServer serves events in ascending order, with pages overlapping by one event.
+type eventsServer struct {
+	*httptest.Server
+	mutex  sync.Mutex
+	events []events.Event
+}
+
+func newEventsServer(t *testing.T) *eventsServer {
+	s := &eventsServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		var begin time.Time
+		if v := r.FormValue("begin"); v != "" {
+			begin, _ = time.Parse("Mon, 2 Jan 2006 15:04:05 -0700", v)
+		}
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+
+		var matched []events.Event
+		for _, e := range s.events {
+			if !e.GetTimestamp().Before(begin) {
+				matched = append(matched, e)
+			}
+		}
+
+		var items []events.Event
+		next := offset
+		if offset < len(matched) {
+			items = matched[offset:min(offset+limit, len(matched))]
+			next = max(offset+len(items)-1, offset+1)
+		}
+		query := r.URL.Query()
+		query.Set("offset", strconv.Itoa(next))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"items":  items,
+			"paging": map[string]string{"next": s.URL + r.URL.Path + "?" + query.Encode()},
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *eventsServer) add(id string, ts time.Time) {
+	e := new(events.Delivered)
+	e.Name = events.EventDelivered
+	e.ID = id
+	e.SetTimestamp(ts)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, e)
+}
+
+func pollIDs(t *testing.T, p *mailgun.EventPoller) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var page []events.Event
+	require.True(t, p.Poll(ctx, &page), "poll failed: %v", p.Err())
+	var ids []string
+	for _, e := range page {
+		ids = append(ids, e.GetID())
+	}
+	return ids
+}
+
+func TestEventPollerCheckpoint(t *testing.T) {
+	srv := newEventsServer(t)
+	begin := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	for i := 1; i <= 3; i++ {
+		srv.add(fmt.Sprintf("e%d", i), begin.Add(time.Duration(i)*time.Second))
+	}
+
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+	store := mailgun.NewMemoryEventCheckpointStore()
+	newPoller := func() *mailgun.EventPoller {
+		return mg.PollEvents(testDomain, &mailgun.ListEventOptions{
+			Begin:        begin,
+			Limit:        2,
+			PollInterval: 10 * time.Millisecond,
+			Checkpoint:   store,
+		})
+	}
+
+	// The second page overlaps the first one.
+	p := newPoller()
+	assert.Equal(t, []string{"e1", "e2"}, pollIDs(t, p))
+	assert.Equal(t, []string{"e3"}, pollIDs(t, p))
+
+	// e3 was not committed, so it is returned again after a restart.
+	p = newPoller()
+	assert.Equal(t, []string{"e3"}, pollIDs(t, p))
+	require.NoError(t, p.Commit(context.Background()))
+
+	cp, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, begin.Add(3*time.Second), cp.Timestamp)
+	assert.Contains(t, cp.Seen, "e3")
+
+	srv.add("e4", begin.Add(4*time.Second))
+	p = newPoller()
+	assert.Equal(t, []string{"e4"}, pollIDs(t, p))
+}
+
+func TestEventPollerSettleDelay(t *testing.T) {
+	srv := newEventsServer(t)
+	begin := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	srv.add("e1", begin.Add(1*time.Second))
+	srv.add("e3", begin.Add(3*time.Second))
+
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+	p := mg.PollEvents(testDomain, &mailgun.ListEventOptions{
+		Begin:        begin,
+		Limit:        10,
+		PollInterval: 10 * time.Millisecond,
+		SettleDelay:  time.Minute,
+	})
+	assert.Equal(t, []string{"e1", "e3"}, pollIDs(t, p))
+
+	// An event stored late, older than the last returned event.
+	srv.add("e2", begin.Add(2*time.Second))
+	assert.Equal(t, []string{"e2"}, pollIDs(t, p))
+}
+
+func TestFileEventCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	store := mailgun.NewFileEventCheckpointStore(filepath.Join(t.TempDir(), "events.checkpoint"))
+
+	cp, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := mailgun.EventCheckpoint{
+		Cursor:    "https://api.mailgun.net/v3/example.com/events/page",
+		Timestamp: ts,
+		Seen:      map[string]time.Time{"id1": ts},
+	}
+	require.NoError(t, store.Save(ctx, want))
+
+	cp, err = store.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, cp)
+	assert.Equal(t, want, *cp)
+}
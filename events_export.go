@@ -0,0 +1,356 @@
+package mailgun
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+)
+
+// EventExportFormat is the output format of ExportEvents().
+type EventExportFormat int
+
+const (
+	// EventExportNDJSON writes one JSON event per line.
+	EventExportNDJSON EventExportFormat = iota
+	// EventExportCSV writes a header line, then one line per event with the columns of EventExportOptions.Columns.
+	EventExportCSV
+)
+
+// DefaultEventExportColumns are the CSV columns written when EventExportOptions.Columns is empty.
+var DefaultEventExportColumns = []string{
+	"timestamp",
+	"id",
+	"event",
+	"log-level",
+	"recipient",
+	"message.headers.message-id",
+	"message.headers.from",
+	"message.headers.subject",
+	"message.size",
+	"delivery-status.code",
+	"delivery-status.message",
+	"severity",
+	"reason",
+	"client-info.client-name",
+	"client-info.device-type",
+	"geolocation.country",
+	"geolocation.city",
+}
+
+// EventExportOptions modifies the behavior of ExportEvents().
+type EventExportOptions struct {
+	// Begin and End are the time range [Begin, End) of the exported events. Required.
+	// They are truncated to whole seconds, the precision of the API.
+	Begin, End time.Time
+	// Window is the duration of the time slices fetched in parallel, truncated to whole seconds.
+	// Defaults to 1 hour.
+	Window time.Duration
+	// Concurrency is the maximum number of windows fetched at the same time. Defaults to 4.
+	Concurrency int
+	// Limit is the number of events per page. Defaults to 300.
+	Limit int
+	// Filter and FilterBy filter the exported events, see ListEventOptions.
+	Filter   map[string]string
+	FilterBy *EventFilter
+	// Format of the output, NDJSON by default.
+	Format EventExportFormat
+	// Columns are the JSON paths of the event fields written as CSV columns,
+	// e.g. "delivery-status.code" or "geolocation.country". Defaults to DefaultEventExportColumns.
+	// The "timestamp" column is written in RFC 3339 format with fractional seconds (time.RFC3339Nano).
+	Columns []string
+	// Checkpoint, if set, saves the end of each window once its events are written,
+	// and makes the export resume after the last saved window.
+	Checkpoint EventCheckpointStore
+}
+
+type exportWindow struct {
+	begin, end time.Time
+}
+
+// exportPage is a page of the events of a window, or the error which ended it.
+type exportPage struct {
+	events []events.Event
+	err    error
+}
+
+// ExportEvents writes the events of the domain between opts.Begin and opts.End to w, in time order.
+// The range is split into windows fetched in parallel with bounded concurrency.
+// It returns the number of events written.
+//
+//	f, _ := os.Create("events.csv")
+//	n, err := mg.ExportEvents(ctx, "example.com", f, &mailgun.EventExportOptions{
+//		Begin:  time.Now().AddDate(0, -1, 0),
+//		End:    time.Now(),
+//		Format: mailgun.EventExportCSV,
+//	})
+func (mg *Client) ExportEvents(ctx context.Context, domain string, w io.Writer, opts *EventExportOptions) (int, error) {
+	if opts == nil || opts.Begin.IsZero() || opts.End.IsZero() {
+		return 0, errors.New("export begin and end times are required")
+	}
+	o := *opts
+	if o.Window <= 0 {
+		o.Window = time.Hour
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 4
+	}
+	if o.Limit <= 0 {
+		o.Limit = 300
+	}
+	if len(o.Columns) == 0 {
+		o.Columns = DefaultEventExportColumns
+	}
+
+	if o.Checkpoint != nil {
+		cp, err := o.Checkpoint.Load(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load export checkpoint: %w", err)
+		}
+		if cp != nil && cp.Timestamp.After(o.Begin) {
+			o.Begin = cp.Timestamp
+		}
+	}
+
+	// The API has a precision of one second: windows must be aligned on whole seconds,
+	// or the events at their edges would be dropped or exported twice.
+	o.Begin = o.Begin.Truncate(time.Second)
+	o.End = o.End.Truncate(time.Second)
+	o.Window = max(o.Window.Truncate(time.Second), time.Second)
+
+	var windows []exportWindow
+	for begin := o.Begin; begin.Before(o.End); begin = begin.Add(o.Window) {
+		windows = append(windows, exportWindow{begin: begin, end: minTime(begin.Add(o.Window), o.End)})
+	}
+
+	ew, err := newEventExportWriter(w, o.Format, o.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The semaphore bounds the windows fetched at the same time. Each window buffers
+	// a single page: it is fetched ahead and waits until the previous windows are written.
+	sem := make(chan struct{}, o.Concurrency)
+	results := make([]chan exportPage, len(windows))
+	for i := range results {
+		results[i] = make(chan exportPage, 1)
+	}
+	go func() {
+		for i, win := range windows {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go mg.exportWindow(ctx, domain, win, &o, results[i])
+		}
+	}()
+
+	var count int
+	for i, win := range windows {
+	pages:
+		for {
+			var (
+				page exportPage
+				ok   bool
+			)
+			select {
+			case page, ok = <-results[i]:
+			case <-ctx.Done():
+				return count, ctx.Err()
+			}
+			if !ok {
+				break pages
+			}
+			if page.err != nil {
+				return count, fmt.Errorf("while exporting events from %s to %s: %w",
+					win.begin.Format(time.RFC3339), win.end.Format(time.RFC3339), page.err)
+			}
+
+			for _, e := range page.events {
+				if err := ew.write(e); err != nil {
+					return count, err
+				}
+				count++
+			}
+			if err := ew.flush(); err != nil {
+				return count, err
+			}
+		}
+
+		if o.Checkpoint != nil {
+			if err := o.Checkpoint.Save(ctx, EventCheckpoint{Timestamp: win.end}); err != nil {
+				return count, fmt.Errorf("failed to save export checkpoint: %w", err)
+			}
+		}
+		<-sem
+	}
+	return count, nil
+}
+
+// exportWindow sends the pages of events of the window in time order to pages, then closes it.
+func (mg *Client) exportWindow(ctx context.Context, domain string, win exportWindow, o *EventExportOptions,
+	pages chan<- exportPage,
+) {
+	defer close(pages)
+	send := func(p exportPage) bool {
+		select {
+		case pages <- p:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	it := mg.ListEvents(domain, &ListEventOptions{
+		Begin:          win.begin,
+		End:            win.end,
+		ForceAscending: true,
+		Limit:          o.Limit,
+		Filter:         o.Filter,
+		FilterBy:       o.FilterBy,
+	})
+
+	var page []events.Event
+	for it.Next(ctx, &page) {
+		var result []events.Event
+		for _, e := range page {
+			// The API has a precision of one second, keep the events of this window only.
+			ts := e.GetTimestamp()
+			if !ts.Before(win.begin) && ts.Before(win.end) {
+				result = append(result, e)
+			}
+		}
+		// Events are listed in ascending order, the pages are written as they arrive.
+		if len(result) != 0 && !send(exportPage{events: result}) {
+			return
+		}
+	}
+	if err := it.Err(); err != nil {
+		send(exportPage{err: err})
+	}
+}
+
+type eventExportWriter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	columns []string
+}
+
+func newEventExportWriter(w io.Writer, format EventExportFormat, columns []string) (*eventExportWriter, error) {
+	switch format {
+	case EventExportNDJSON:
+		return &eventExportWriter{w: w}, nil
+	case EventExportCSV:
+		ew := &eventExportWriter{w: w, csv: csv.NewWriter(w), columns: columns}
+		if err := ew.csv.Write(columns); err != nil {
+			return nil, fmt.Errorf("while writing CSV header: %w", err)
+		}
+		return ew, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %d", format)
+	}
+}
+
+func (ew *eventExportWriter) write(e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("while encoding event '%s': %w", e.GetID(), err)
+	}
+
+	if ew.csv == nil {
+		if _, err := ew.w.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("while writing event: %w", err)
+		}
+		return nil
+	}
+
+	flat, err := flattenEventJSON(data)
+	if err != nil {
+		return fmt.Errorf("while flattening event '%s': %w", e.GetID(), err)
+	}
+	record := make([]string, len(ew.columns))
+	for i, column := range ew.columns {
+		if column == "timestamp" {
+			record[i] = e.GetTimestamp().Format(time.RFC3339Nano)
+			continue
+		}
+		record[i] = flat[column]
+	}
+	if err := ew.csv.Write(record); err != nil {
+		return fmt.Errorf("while writing event: %w", err)
+	}
+	return nil
+}
+
+func (ew *eventExportWriter) flush() error {
+	if ew.csv == nil {
+		return nil
+	}
+	ew.csv.Flush()
+	if err := ew.csv.Error(); err != nil {
+		return fmt.Errorf("while writing events: %w", err)
+	}
+	return nil
+}
+
+// flattenEventJSON returns the scalar values of the JSON object by dotted path, e.g. "message.headers.from".
+// Arrays are kept as JSON.
+func flattenEventJSON(data []byte) (map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	var flatten func(prefix string, v any) error
+	flatten = func(prefix string, v any) error {
+		switch val := v.(type) {
+		case map[string]any:
+			for k, child := range val {
+				key := k
+				if prefix != "" {
+					key = prefix + "." + k
+				}
+				if err := flatten(key, child); err != nil {
+					return err
+				}
+			}
+		case nil:
+			result[prefix] = ""
+		case string:
+			result[prefix] = val
+		case json.Number:
+			result[prefix] = val.String()
+		case bool:
+			result[prefix] = strconv.FormatBool(val)
+		default:
+			b, err := json.Marshal(val)
+			if err != nil {
+				return err
+			}
+			result[prefix] = string(b)
+		}
+		return nil
+	}
+	return result, flatten("", obj)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
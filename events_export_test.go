@@ -0,0 +1,163 @@
+package mailgun_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportServer serves one delivered event every 7 minutes from base, for 2 hours,
+// answering the windows in random order.
+func newExportServer(t *testing.T, base time.Time) *httptest.Server {
+	var all []events.Event
+	for d := time.Duration(0); d < 2*time.Hour; d += 7 * time.Minute {
+		e := &events.Delivered{Recipient: "joe@example.com"}
+		e.Name = events.EventDelivered
+		e.ID = d.String()
+		e.SetTimestamp(base.Add(d))
+		e.DeliveryStatus.Code = 250
+		e.Message.Headers.Subject = "Hello, world"
+		all = append(all, e)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Duration(rand.IntN(20)) * time.Millisecond)
+		if r.FormValue("page") != "" {
+			_, _ = w.Write([]byte(`{"items": [], "paging": {}}`))
+			return
+		}
+
+		const layout = "Mon, 2 Jan 2006 15:04:05 -0700"
+		begin, err := time.Parse(layout, r.FormValue("begin"))
+		require.NoError(t, err)
+		end, err := time.Parse(layout, r.FormValue("end"))
+		require.NoError(t, err)
+
+		var items []events.Event
+		for _, e := range all {
+			if !e.GetTimestamp().Before(begin) && !e.GetTimestamp().After(end) {
+				items = append(items, e)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"items":  items,
+			"paging": map[string]string{"next": "http://" + r.Host + r.URL.Path + "?page=2"},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestExportEventsNDJSON(t *testing.T) {
+	base := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	srv := newExportServer(t, base)
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	var buf bytes.Buffer
+	n, err := mg.ExportEvents(context.Background(), testDomain, &buf, &mailgun.EventExportOptions{
+		Begin:       base,
+		End:         base.Add(2 * time.Hour),
+		Window:      10 * time.Minute,
+		Concurrency: 4,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 18, n)
+
+	var ids []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		e, err := events.ParseEvent(scanner.Bytes())
+		require.NoError(t, err)
+		ids = append(ids, e.GetID())
+	}
+	require.Len(t, ids, 18)
+	for i, id := range ids {
+		assert.Equal(t, (time.Duration(i) * 7 * time.Minute).String(), id)
+	}
+}
+
+func TestExportEventsSubSecond(t *testing.T) {
+	base := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	srv := newExportServer(t, base)
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	// Times are aligned on whole seconds, as the API queries.
+	var buf bytes.Buffer
+	n, err := mg.ExportEvents(context.Background(), testDomain, &buf, &mailgun.EventExportOptions{
+		Begin:  base.Add(500 * time.Millisecond),
+		End:    base.Add(2*time.Hour + 500*time.Millisecond),
+		Window: 10*time.Minute + 250*time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 18, n)
+}
+
+func TestExportEventsCSVResume(t *testing.T) {
+	base := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	srv := newExportServer(t, base)
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+	ctx := context.Background()
+
+	store := mailgun.NewMemoryEventCheckpointStore()
+	require.NoError(t, store.Save(ctx, mailgun.EventCheckpoint{Timestamp: base.Add(time.Hour)}))
+
+	var buf bytes.Buffer
+	n, err := mg.ExportEvents(ctx, testDomain, &buf, &mailgun.EventExportOptions{
+		Begin:      base,
+		End:        base.Add(2 * time.Hour),
+		Window:     15 * time.Minute,
+		Format:     mailgun.EventExportCSV,
+		Columns:    []string{"timestamp", "id", "delivery-status.code", "message.headers.subject", "geolocation.country"},
+		Checkpoint: store,
+	})
+	require.NoError(t, err)
+	// Events at 63, 70, ..., 119 minutes.
+	assert.Equal(t, 9, n)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 10)
+	assert.Equal(t, []string{"timestamp", "id", "delivery-status.code", "message.headers.subject", "geolocation.country"}, records[0])
+	assert.Equal(t, []string{"2024-01-02T01:03:00Z", (63 * time.Minute).String(), "250", "Hello, world", ""}, records[1])
+
+	cp, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, base.Add(2*time.Hour), cp.Timestamp)
+}
+
+func TestExportEventsErrors(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	_, err := mg.ExportEvents(context.Background(), testDomain, &bytes.Buffer{}, &mailgun.EventExportOptions{})
+	require.Error(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"message": "oops"}`)
+	}))
+	defer srv.Close()
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	base := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	_, err = mg.ExportEvents(context.Background(), testDomain, &bytes.Buffer{}, &mailgun.EventExportOptions{
+		Begin:  base,
+		End:    base.Add(time.Hour),
+		Window: time.Minute,
+	})
+	assert.Equal(t, http.StatusInternalServerError, mailgun.GetStatusFromErr(err))
+}
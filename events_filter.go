@@ -0,0 +1,300 @@
+package mailgun
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+)
+
+// Event filter fields, see https://documentation.mailgun.com/docs/mailgun/user-manual/events/#filter-field
+const (
+	FilterFieldEvent      = "event"
+	FilterFieldList       = "list"
+	FilterFieldAttachment = "attachment"
+	FilterFieldFrom       = "from"
+	FilterFieldMessageID  = "message-id"
+	FilterFieldSubject    = "subject"
+	FilterFieldTo         = "to"
+	FilterFieldSize       = "size"
+	FilterFieldRecipient  = "recipient"
+	FilterFieldRecipients = "recipients"
+	FilterFieldTags       = "tags"
+	FilterFieldSeverity   = "severity"
+)
+
+var filterEventNames = map[string]bool{
+	events.EventAccepted:              true,
+	events.EventRejected:              true,
+	events.EventDelivered:             true,
+	events.EventFailed:                true,
+	events.EventOpened:                true,
+	events.EventClicked:               true,
+	events.EventUnsubscribed:          true,
+	events.EventComplained:            true,
+	events.EventStored:                true,
+	events.EventDropped:               true,
+	events.EventListMemberUploaded:    true,
+	events.EventListMemberUploadError: true,
+	events.EventListUploaded:          true,
+}
+
+var filterSeverities = map[string]bool{
+	events.SeverityTemporary: true,
+	events.SeverityPermanent: true,
+}
+
+// FilterExpr is the value of an event filter field: a single term,
+// or a boolean composition of terms built with FilterOr, FilterAnd and FilterNot.
+type FilterExpr struct {
+	op    string
+	value string
+	args  []FilterExpr
+}
+
+// FilterEq matches events whose field equals the value.
+func FilterEq(value string) FilterExpr {
+	return FilterExpr{value: value}
+}
+
+// FilterAnyOf matches events whose field equals any of the values.
+func FilterAnyOf(values ...string) FilterExpr {
+	args := make([]FilterExpr, 0, len(values))
+	for _, v := range values {
+		args = append(args, FilterEq(v))
+	}
+	return FilterOr(args...)
+}
+
+// FilterOr matches events matched by any of the expressions.
+func FilterOr(exprs ...FilterExpr) FilterExpr {
+	return FilterExpr{op: "OR", args: exprs}
+}
+
+// FilterAnd matches events matched by all the expressions.
+func FilterAnd(exprs ...FilterExpr) FilterExpr {
+	return FilterExpr{op: "AND", args: exprs}
+}
+
+// FilterNot matches events not matched by the expression.
+func FilterNot(expr FilterExpr) FilterExpr {
+	return FilterExpr{op: "NOT", args: []FilterExpr{expr}}
+}
+
+// FilterSizeGreater matches messages larger than size bytes. Only valid for the size field.
+func FilterSizeGreater(size int) FilterExpr {
+	return FilterExpr{value: ">" + strconv.Itoa(size)}
+}
+
+// FilterSizeLess matches messages smaller than size bytes. Only valid for the size field.
+func FilterSizeLess(size int) FilterExpr {
+	return FilterExpr{value: "<" + strconv.Itoa(size)}
+}
+
+// String renders the expression in the Mailgun filter syntax, e.g. `failed OR rejected`.
+func (e FilterExpr) String() string {
+	switch e.op {
+	case "":
+		return quoteFilterTerm(e.value)
+	case "NOT":
+		return "NOT " + e.args[0].group()
+	default:
+		parts := make([]string, 0, len(e.args))
+		for _, arg := range e.args {
+			parts = append(parts, arg.group())
+		}
+		return strings.Join(parts, " "+e.op+" ")
+	}
+}
+
+// group returns the expression in parentheses if it is a composition of several terms.
+func (e FilterExpr) group() string {
+	if (e.op == "OR" || e.op == "AND") && len(e.args) > 1 {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// validate checks the expression structure and every term against the field validator.
+func (e FilterExpr) validate(field string, validTerm func(string) error) error {
+	switch e.op {
+	case "":
+		if e.value == "" {
+			return fmt.Errorf("empty term in filter '%s'", field)
+		}
+		return validTerm(e.value)
+	case "NOT":
+		if len(e.args) != 1 {
+			return fmt.Errorf("NOT requires exactly one expression in filter '%s'", field)
+		}
+	default:
+		if len(e.args) == 0 {
+			return fmt.Errorf("%s requires at least one expression in filter '%s'", e.op, field)
+		}
+	}
+
+	for _, arg := range e.args {
+		if err := arg.validate(field, validTerm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func quoteFilterTerm(v string) string {
+	switch v {
+	case "OR", "AND", "NOT":
+		return strconv.Quote(v)
+	}
+	if strings.ContainsAny(v, " \t()\"") {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// EventFilter builds the Filter of ListEventOptions using typed fields.
+// Every field is validated by Build, before any request is made.
+//
+//	filter := mailgun.NewEventFilter().
+//		Event(mailgun.FilterAnyOf(events.EventFailed, events.EventRejected)).
+//		Severity(mailgun.FilterEq(events.SeverityPermanent)).
+//		Tags(mailgun.FilterNot(mailgun.FilterEq("newsletter")))
+//
+//	it := mg.ListEvents("example.com", &mailgun.ListEventOptions{FilterBy: filter})
+type EventFilter struct {
+	fields map[string]FilterExpr
+	order  []string
+}
+
+// NewEventFilter creates an empty event filter.
+func NewEventFilter() *EventFilter {
+	return &EventFilter{fields: make(map[string]FilterExpr)}
+}
+
+// Where sets the expression of an arbitrary filter field.
+func (f *EventFilter) Where(field string, expr FilterExpr) *EventFilter {
+	if f.fields == nil {
+		f.fields = make(map[string]FilterExpr)
+	}
+	if _, ok := f.fields[field]; !ok {
+		f.order = append(f.order, field)
+	}
+	f.fields[field] = expr
+	return f
+}
+
+// Event filters by event type, e.g. events.EventFailed.
+func (f *EventFilter) Event(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldEvent, expr)
+}
+
+// List filters by the address of a mailing list.
+func (f *EventFilter) List(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldList, expr)
+}
+
+// Attachment filters by attachment file name.
+func (f *EventFilter) Attachment(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldAttachment, expr)
+}
+
+// From filters by the From: header.
+func (f *EventFilter) From(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldFrom, expr)
+}
+
+// MessageID filters by the Message-Id header, without the angle brackets.
+func (f *EventFilter) MessageID(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldMessageID, expr)
+}
+
+// Subject filters by the Subject: header.
+func (f *EventFilter) Subject(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldSubject, expr)
+}
+
+// To filters by the To: header.
+func (f *EventFilter) To(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldTo, expr)
+}
+
+// Size filters by message size, see FilterSizeGreater and FilterSizeLess.
+func (f *EventFilter) Size(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldSize, expr)
+}
+
+// Recipient filters by the recipient of the event.
+func (f *EventFilter) Recipient(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldRecipient, expr)
+}
+
+// Recipients filters by any of the recipients of the message.
+func (f *EventFilter) Recipients(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldRecipients, expr)
+}
+
+// Tags filters by message tags.
+func (f *EventFilter) Tags(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldTags, expr)
+}
+
+// Severity filters failed events by severity, e.g. events.SeverityPermanent.
+func (f *EventFilter) Severity(expr FilterExpr) *EventFilter {
+	return f.Where(FilterFieldSeverity, expr)
+}
+
+// Build validates the filter and returns the query parameters to use as ListEventOptions.Filter.
+func (f *EventFilter) Build() (map[string]string, error) {
+	result := make(map[string]string, len(f.fields))
+	var errs []error
+	for _, field := range f.order {
+		expr := f.fields[field]
+		if err := expr.validate(field, filterTermValidator(field)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result[field] = expr.String()
+	}
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("invalid event filter: %w", errors.Join(errs...))
+	}
+	return result, nil
+}
+
+func filterTermValidator(field string) func(string) error {
+	switch field {
+	case FilterFieldEvent:
+		return func(v string) error {
+			if !filterEventNames[strings.ToLower(v)] && !events.IsRegisteredEvent(v) {
+				return fmt.Errorf("unknown event '%s'", v)
+			}
+			return nil
+		}
+	case FilterFieldSeverity:
+		return func(v string) error {
+			if !filterSeverities[strings.ToLower(v)] {
+				return fmt.Errorf("unknown severity '%s'", v)
+			}
+			return nil
+		}
+	case FilterFieldSize:
+		return func(v string) error {
+			if len(v) < 2 || (v[0] != '>' && v[0] != '<') {
+				return fmt.Errorf("size must be FilterSizeGreater or FilterSizeLess, got '%s'", v)
+			}
+			if _, err := strconv.Atoi(v[1:]); err != nil {
+				return fmt.Errorf("invalid size '%s'", v)
+			}
+			return nil
+		}
+	case FilterFieldList, FilterFieldAttachment, FilterFieldFrom, FilterFieldMessageID, FilterFieldSubject,
+		FilterFieldTo, FilterFieldRecipient, FilterFieldRecipients, FilterFieldTags:
+		return func(string) error { return nil }
+	default:
+		return func(string) error {
+			return fmt.Errorf("unknown filter field '%s'", field)
+		}
+	}
+}
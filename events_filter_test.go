@@ -0,0 +1,92 @@
+package mailgun_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFilterBuild(t *testing.T) {
+	filter, err := mailgun.NewEventFilter().
+		Event(mailgun.FilterAnyOf(events.EventFailed, events.EventRejected)).
+		Severity(mailgun.FilterEq(events.SeverityPermanent)).
+		Subject(mailgun.FilterAnd(mailgun.FilterEq("Hello world"), mailgun.FilterNot(mailgun.FilterAnyOf("draft", "test")))).
+		MessageID(mailgun.FilterEq("20240101.1@example.com")).
+		Attachment(mailgun.FilterEq("invoice.pdf")).
+		Size(mailgun.FilterSizeGreater(10000)).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"event":      "failed OR rejected",
+		"severity":   "permanent",
+		"subject":    `"Hello world" AND NOT (draft OR test)`,
+		"message-id": "20240101.1@example.com",
+		"attachment": "invoice.pdf",
+		"size":       ">10000",
+	}, filter)
+}
+
+func TestEventFilterZeroValue(t *testing.T) {
+	filter, err := (&mailgun.EventFilter{}).Event(mailgun.FilterEq(events.EventDelivered)).Build()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"event": "delivered"}, filter)
+}
+
+func TestEventFilterValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *mailgun.EventFilter
+	}{
+		{name: "unknown event", filter: mailgun.NewEventFilter().Event(mailgun.FilterEq("bounced"))},
+		{name: "unknown severity", filter: mailgun.NewEventFilter().Severity(mailgun.FilterEq("fatal"))},
+		{name: "size without comparison", filter: mailgun.NewEventFilter().Size(mailgun.FilterEq("100"))},
+		{name: "empty term", filter: mailgun.NewEventFilter().Tags(mailgun.FilterEq(""))},
+		{name: "empty or", filter: mailgun.NewEventFilter().Tags(mailgun.FilterAnyOf())},
+		{name: "unknown field", filter: mailgun.NewEventFilter().Where("color", mailgun.FilterEq("red"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.filter.Build()
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestListEventsFilterBy(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{"items":[],"paging":{}}`))
+	}))
+	defer srv.Close()
+
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+	ctx := context.Background()
+
+	it := mg.ListEvents(testDomain, &mailgun.ListEventOptions{
+		Filter:   map[string]string{"event": "delivered", "tags": "newsletter"},
+		FilterBy: mailgun.NewEventFilter().Event(mailgun.FilterEq(events.EventFailed)),
+	})
+	var page []events.Event
+	it.Next(ctx, &page)
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"failed"}, query["event"])
+	assert.Equal(t, []string{"newsletter"}, query["tags"])
+
+	// An invalid filter fails before any request is made.
+	query = nil
+	it = mg.ListEvents(testDomain, &mailgun.ListEventOptions{
+		FilterBy: mailgun.NewEventFilter().Event(mailgun.FilterEq("bounced")),
+	})
+	assert.False(t, it.Next(ctx, &page))
+	require.Error(t, it.Err())
+	assert.Nil(t, query)
+}
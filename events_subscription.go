@@ -0,0 +1,255 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+)
+
+// DomainEvent is an event delivered by a Subscription, with the domain it belongs to.
+type DomainEvent struct {
+	Domain string
+	Event  events.Event
+}
+
+// SubscriptionError is an error of the poller of a domain. The poller is restarted after the poll interval.
+type SubscriptionError struct {
+	Domain string
+	Err    error
+}
+
+func (e *SubscriptionError) Error() string {
+	return fmt.Sprintf("while polling events of domain '%s': %s", e.Domain, e.Err)
+}
+
+func (e *SubscriptionError) Unwrap() error {
+	return e.Err
+}
+
+// SubscribeOptions modifies the behavior of Subscribe().
+type SubscribeOptions struct {
+	// Begin is the time of the first events when a domain has no checkpoint. Defaults to 30 minutes ago.
+	Begin time.Time
+	// Limit, PollInterval, DedupeWindow and SettleDelay configure the poller of each domain, see ListEventOptions.
+	Limit        int
+	PollInterval time.Duration
+	DedupeWindow time.Duration
+	SettleDelay  time.Duration
+	// Checkpoint returns the checkpoint store of a domain. Defaults to an in-memory store per domain,
+	// so a poller restarted after an error resumes where it stopped.
+	Checkpoint func(domain string) EventCheckpointStore
+	// Buffer is the capacity of the channels of the subscribers. Defaults to 100, use a negative value
+	// for unbuffered channels.
+	Buffer int
+}
+
+// Subscription polls the events of several domains in the background and delivers them
+// to every subscriber channel. A slow subscriber slows the delivery to every subscriber down,
+// so every subscriber channel must be read until it is closed.
+//
+// The checkpoint of a domain is saved once its events have been sent to every subscriber channel,
+// so use unbuffered channels for events to be checkpointed only once the subscribers received them.
+//
+//	sub := mg.Subscribe(ctx, []string{"a.example.com", "b.example.com"},
+//		mailgun.NewEventFilter().Event(mailgun.FilterEq(events.EventFailed)), nil)
+//	defer sub.Close()
+//
+//	for {
+//		select {
+//		case e, ok := <-sub.Events():
+//			if !ok {
+//				return
+//			}
+//			fmt.Printf("%s: %s\n", e.Domain, e.Event.GetName())
+//		case err, ok := <-sub.Errors():
+//			if ok {
+//				log.Print(err)
+//			}
+//		}
+//	}
+type Subscription struct {
+	mg     Mailgun
+	filter *EventFilter
+	opts   SubscribeOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	errors chan error
+
+	eventsOnce sync.Once
+	events     <-chan DomainEvent
+
+	mutex       sync.Mutex
+	subscribers []chan DomainEvent
+	closed      bool
+}
+
+// Subscribe starts polling the events of the domains matching the filter, which can be nil.
+// The subscription stops when the context is cancelled or Close is called.
+func (mg *Client) Subscribe(ctx context.Context, domains []string, filter *EventFilter, opts *SubscribeOptions) *Subscription {
+	s := &Subscription{
+		mg:     mg,
+		filter: filter,
+		errors: make(chan error, len(domains)+16),
+	}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Begin.IsZero() {
+		s.opts.Begin = time.Now().Add(-30 * time.Minute)
+	}
+	if s.opts.PollInterval <= 0 {
+		s.opts.PollInterval = 15 * time.Second
+	}
+	if s.opts.Buffer == 0 {
+		s.opts.Buffer = 100
+	}
+	s.opts.Buffer = max(s.opts.Buffer, 0)
+	if s.opts.Checkpoint == nil {
+		s.opts.Checkpoint = func(string) EventCheckpointStore {
+			return NewMemoryEventCheckpointStore()
+		}
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, domain := range domains {
+		s.wg.Add(1)
+		go s.run(domain)
+	}
+	go func() {
+		<-s.ctx.Done()
+		s.wg.Wait()
+		s.closeSubscribers()
+	}()
+	return s
+}
+
+// Events returns the channel of the default subscriber, added by the first call as with AddSubscriber:
+// it receives the events delivered from then on. The channel is closed once the subscription has stopped.
+func (s *Subscription) Events() <-chan DomainEvent {
+	s.eventsOnce.Do(func() {
+		s.events = s.AddSubscriber()
+	})
+	return s.events
+}
+
+// AddSubscriber adds a subscriber receiving all the events delivered from now on.
+// The channel is closed once the subscription has stopped, and must be read until then.
+func (s *Subscription) AddSubscriber() <-chan DomainEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ch := make(chan DomainEvent, s.opts.Buffer)
+	if s.closed {
+		close(ch)
+		return ch
+	}
+	s.subscribers = append(s.subscribers, ch)
+	return ch
+}
+
+// Errors returns the errors of the domain pollers, as *SubscriptionError.
+// Errors are dropped when the channel is full. The channel is closed once the subscription has stopped.
+func (s *Subscription) Errors() <-chan error {
+	return s.errors
+}
+
+// Done is closed once the subscription is stopping.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Close stops the pollers, saves the checkpoints of the events already delivered,
+// and waits for the pollers to exit. The subscriber and error channels are closed.
+func (s *Subscription) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	s.closeSubscribers()
+	return nil
+}
+
+func (s *Subscription) closeSubscribers() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for _, ch := range s.subscribers {
+		close(ch)
+	}
+	// The pollers have exited, nothing reports errors anymore.
+	close(s.errors)
+}
+
+// run polls the events of the domain until the subscription stops, restarting the poller after errors.
+func (s *Subscription) run(domain string) {
+	defer s.wg.Done()
+
+	checkpoint := s.opts.Checkpoint(domain)
+	for {
+		poller := s.mg.PollEvents(domain, &ListEventOptions{
+			Begin:        s.opts.Begin,
+			Limit:        s.opts.Limit,
+			PollInterval: s.opts.PollInterval,
+			DedupeWindow: s.opts.DedupeWindow,
+			SettleDelay:  s.opts.SettleDelay,
+			FilterBy:     s.filter,
+			Checkpoint:   checkpoint,
+		})
+
+		var page []events.Event
+		for poller.Poll(s.ctx, &page) {
+			if !s.deliver(domain, page) {
+				// Cancelled before every event was delivered, keep the previous checkpoint.
+				return
+			}
+		}
+
+		if s.ctx.Err() != nil {
+			// The last page was delivered, save its checkpoint before stopping.
+			if err := poller.Commit(context.WithoutCancel(s.ctx)); err != nil {
+				s.reportError(domain, err)
+			}
+			return
+		}
+		s.reportError(domain, poller.Err())
+
+		timer := time.NewTimer(s.opts.PollInterval)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// deliver sends the events to every subscriber, returning false if the subscription stopped first.
+func (s *Subscription) deliver(domain string, page []events.Event) bool {
+	s.mutex.Lock()
+	subscribers := s.subscribers
+	s.mutex.Unlock()
+
+	for _, e := range page {
+		for _, ch := range subscribers {
+			select {
+			case ch <- DomainEvent{Domain: domain, Event: e}:
+			case <-s.ctx.Done():
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *Subscription) reportError(domain string, err error) {
+	select {
+	case s.errors <- &SubscriptionError{Domain: domain, Err: err}:
+	default:
+	}
+}
@@ -0,0 +1,147 @@
+package mailgun_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	srv := newEventsServer(t)
+	begin := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	for i := 1; i <= 3; i++ {
+		srv.add(fmt.Sprintf("e%d", i), begin.Add(time.Duration(i)*time.Second))
+	}
+
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	var mutex sync.Mutex
+	stores := make(map[string]*mailgun.MemoryEventCheckpointStore)
+	// The pollers wait for the subscribers to be added.
+	ready := make(chan struct{})
+	sub := mg.Subscribe(context.Background(), []string{"a.test", "b.test"}, nil, &mailgun.SubscribeOptions{
+		Begin:        begin,
+		Limit:        10,
+		PollInterval: 10 * time.Millisecond,
+		Checkpoint: func(domain string) mailgun.EventCheckpointStore {
+			<-ready
+			mutex.Lock()
+			defer mutex.Unlock()
+			stores[domain] = mailgun.NewMemoryEventCheckpointStore()
+			return stores[domain]
+		},
+	})
+	first := sub.Events()
+	second := sub.AddSubscriber()
+	close(ready)
+
+	received := func(ch <-chan mailgun.DomainEvent) map[string][]string {
+		result := make(map[string][]string)
+		timeout := time.After(5 * time.Second)
+		for n := 0; n < 6; n++ {
+			select {
+			case e := <-ch:
+				result[e.Domain] = append(result[e.Domain], e.Event.GetID())
+			case <-timeout:
+				t.Fatal("timeout waiting for events")
+			}
+		}
+		return result
+	}
+	want := map[string][]string{"a.test": {"e1", "e2", "e3"}, "b.test": {"e1", "e2", "e3"}}
+	assert.Equal(t, want, received(first))
+	assert.Equal(t, want, received(second))
+
+	require.NoError(t, sub.Close())
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	_, ok = <-second
+	assert.False(t, ok)
+	_, ok = <-sub.Errors()
+	assert.False(t, ok)
+
+	for _, domain := range []string{"a.test", "b.test"} {
+		cp, err := stores[domain].Load(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, cp, domain)
+		assert.Equal(t, begin.Add(3*time.Second), cp.Timestamp)
+	}
+}
+
+func TestSubscribeWithoutDefaultSubscriber(t *testing.T) {
+	srv := newEventsServer(t)
+	begin := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	for i := 1; i <= 3; i++ {
+		srv.add(fmt.Sprintf("e%d", i), begin.Add(time.Duration(i)*time.Second))
+	}
+
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	// The default subscriber is not added until Events is called: it does not block the delivery.
+	ready := make(chan struct{})
+	sub := mg.Subscribe(context.Background(), []string{"a.test"}, nil, &mailgun.SubscribeOptions{
+		Begin:        begin,
+		Limit:        10,
+		PollInterval: 10 * time.Millisecond,
+		Buffer:       -1,
+		Checkpoint: func(string) mailgun.EventCheckpointStore {
+			<-ready
+			return mailgun.NewMemoryEventCheckpointStore()
+		},
+	})
+	defer sub.Close()
+	ch := sub.AddSubscriber()
+	close(ready)
+
+	timeout := time.After(5 * time.Second)
+	for _, id := range []string{"e1", "e2", "e3"} {
+		select {
+		case e := <-ch:
+			assert.Equal(t, id, e.Event.GetID())
+		case <-timeout:
+			t.Fatal("timeout waiting for events")
+		}
+	}
+}
+
+func TestSubscribeErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := mg.Subscribe(ctx, []string{"bad.test"}, nil, &mailgun.SubscribeOptions{PollInterval: 10 * time.Millisecond})
+
+	select {
+	case err := <-sub.Errors():
+		var subErr *mailgun.SubscriptionError
+		require.True(t, errors.As(err, &subErr))
+		assert.Equal(t, "bad.test", subErr.Domain)
+		assert.Equal(t, http.StatusInternalServerError, mailgun.GetStatusFromErr(err))
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for an error")
+	}
+
+	// Cancelling the context stops the subscription.
+	cancel()
+	<-sub.Done()
+	for range sub.Events() {
+	}
+	for range sub.Errors() {
+	}
+}
@@ -3,6 +3,8 @@ package mailgun_test
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -166,3 +168,25 @@ func ExampleMailgun_ListEvents() {
 	// Unsubscribed client OS: OS X
 	// Unsubscribed client OS: OS X
 }
+
+func TestEventIteratorUnknownEvent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"items":[
+			{"event": "delivered", "id": "1"},
+			{"event": "brand_new_event", "id": "2"}
+		],"paging":{}}`))
+	}))
+	defer srv.Close()
+
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	var page []events.Event
+	it := mg.ListEvents(testDomain, nil)
+	require.True(t, it.Next(context.Background(), &page))
+	require.NoError(t, it.Err())
+	require.Len(t, page, 2)
+	assert.IsType(t, &events.Delivered{}, page[0])
+	assert.IsType(t, &events.Unknown{}, page[1])
+	assert.Equal(t, "brand_new_event", page[1].GetName())
+}
@@ -14,6 +14,7 @@ import (
 type ListMonitoredDomainsOptions = inboxready.GETV1InboxreadyDomainsParams
 
 type MonitoredDomainsIterator struct {
+	mg      Mailgun
 	opts    ListMonitoredDomainsOptions
 	req     *httpRequest
 	resp    inboxready.InboxReadyGithubComMailgunInboxreadyAPIDomainListResponse
@@ -24,20 +25,27 @@ type MonitoredDomainsIterator struct {
 func (mg *Client) ListMonitoredDomains(opts ListMonitoredDomainsOptions) (*MonitoredDomainsIterator, error) {
 	// TODO(vtopc): support opts.Domain
 
+	uri := generateApiUrl(mg, mtypes.InboxreadyDomainsVersion, mtypes.InboxreadyDomainsEndpoint)
+	return mg.newMonitoredDomainsIterator(uri, opts), nil
+}
+
+// newMonitoredDomainsIterator creates an iterator starting at the page of uri, or done if uri is empty.
+func (mg *Client) newMonitoredDomainsIterator(uri string, opts ListMonitoredDomainsOptions) *MonitoredDomainsIterator {
 	if opts.Limit == nil || *opts.Limit == 0 {
 		opts.Limit = ptr(10)
 	}
 
-	req := newHTTPRequest(generateApiUrl(mg, mtypes.InboxreadyDomainsVersion, mtypes.InboxreadyDomainsEndpoint))
+	req := newHTTPRequest(uri)
 	req.addParameter("limit", strconv.Itoa(*opts.Limit))
 	req.setClient(mg.HTTPClient())
 	req.setBasicAuth(basicAuthUser, mg.APIKey())
 
 	return &MonitoredDomainsIterator{
+		mg:      mg,
 		opts:    opts,
 		req:     req,
-		isFirst: true,
-	}, nil
+		isFirst: uri != "",
+	}
 }
 
 func (iter *MonitoredDomainsIterator) Err() error {
@@ -55,6 +63,9 @@ func (iter *MonitoredDomainsIterator) Next(ctx context.Context, resp []mtypes.Mo
 	if iter.isFirst {
 		iter.isFirst = false
 	} else {
+		if iter.resp.Paging == nil || iter.resp.Paging.Next == "" {
+			return false
+		}
 		iter.req.URL = iter.resp.Paging.Next
 	}
 
@@ -0,0 +1,27 @@
+// Package msgid handles the message identifiers of the e-mail headers, e.g. Message-Id or Content-ID.
+package msgid
+
+import (
+	"regexp"
+	"strings"
+)
+
+var pattern = regexp.MustCompile(`<([^<>\s]+)>`)
+
+// Trim returns the message ID without the surrounding spaces and angle brackets.
+func Trim(id string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(id), "<"), ">")
+}
+
+// Parse returns the message IDs of a header listing several, e.g. References, without the angle brackets.
+// IDs without angle brackets are split on whitespace.
+func Parse(s string) []string {
+	var ids []string
+	for _, m := range pattern.FindAllStringSubmatch(s, -1) {
+		ids = append(ids, m[1])
+	}
+	if len(ids) == 0 {
+		ids = strings.Fields(s)
+	}
+	return ids
+}
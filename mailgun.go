@@ -78,13 +78,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mailgun/mailgun-go/v5/events"
 	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/mailgun/mailgun-go/v5/webhooks"
 )
 
 // Debug set true to write the HTTP requests in curl for to stdout
@@ -121,6 +126,8 @@ const (
 	templatesEndpoint    = "templates"
 	accountsEndpoint     = "accounts"
 	subaccountsEndpoint  = "subaccounts"
+	signingKeyEndpoint   = "http_signing_key"
+	envelopesEndpoint    = "envelopes"
 )
 
 // Mailgun defines the supported subset of the Mailgun API.
@@ -147,20 +154,29 @@ type Mailgun interface {
 	// Send attempts to queue a message (see CommonMessage, NewMessage, and its methods) for delivery.
 	Send(ctx context.Context, m Message) (mtypes.SendMessageResponse, error)
 	ReSend(ctx context.Context, url string, recipients ...string) (mtypes.SendMessageResponse, error)
+	GetDomainSendingQueues(ctx context.Context, domain string) (mtypes.DomainSendingQueues, error)
+	DeleteScheduledMessages(ctx context.Context, domain string) error
 
 	ListBounces(domain string, opts *ListOptions) *BouncesIterator
+	AllBounces(ctx context.Context, domain string, opts *ListOptions) iter.Seq2[mtypes.Bounce, error]
+	ResumeBounces(cursor string) (*BouncesIterator, error)
 	GetBounce(ctx context.Context, domain, address string) (mtypes.Bounce, error)
 	AddBounce(ctx context.Context, domain, address, code, err string) error
 	DeleteBounce(ctx context.Context, domain, address string) error
 	DeleteBounceList(ctx context.Context, domain string) error
 
 	ListMetrics(opts MetricsOptions) (*MetricsIterator, error)
+	AllMetrics(ctx context.Context, opts MetricsOptions) iter.Seq2[mtypes.MetricsItem, error]
 
 	GetTag(ctx context.Context, domain, tag string) (mtypes.Tag, error)
 	DeleteTag(ctx context.Context, domain, tag string) error
 	ListTags(domain string, opts *ListTagOptions) *TagIterator
+	AllTags(ctx context.Context, domain string, opts *ListTagOptions) iter.Seq2[mtypes.Tag, error]
+	ResumeTags(cursor string) (*TagIterator, error)
 
 	ListDomains(opts *ListDomainsOptions) *DomainsIterator
+	AllDomains(ctx context.Context, opts *ListDomainsOptions) iter.Seq2[mtypes.Domain, error]
+	ResumeDomains(cursor string) (*DomainsIterator, error)
 	GetDomain(ctx context.Context, domain string, opts *GetDomainOptions) (mtypes.GetDomainResponse, error)
 	CreateDomain(ctx context.Context, domain string, opts *CreateDomainOptions) (mtypes.GetDomainResponse, error)
 	VerifyDomain(ctx context.Context, domain string) (mtypes.GetDomainResponse, error)
@@ -169,6 +185,8 @@ type Mailgun interface {
 	// Deprecated: use VerifyDomain instead.
 	VerifyAndReturnDomain(ctx context.Context, domain string) (mtypes.GetDomainResponse, error)
 	ListIPDomains(ip string, opts *ListIPDomainOptions) *IPDomainsIterator
+	AllIPDomains(ctx context.Context, ip string, opts *ListIPDomainOptions) iter.Seq2[mtypes.DomainIPs, error]
+	ResumeIPDomains(cursor string) (*IPDomainsIterator, error)
 
 	// Deprecated: use UpdateDomain instead
 	UpdateDomainConnection(ctx context.Context, domain string, dc mtypes.DomainConnection) error
@@ -181,10 +199,12 @@ type Mailgun interface {
 	UpdateOpenTracking(ctx context.Context, domain, active string) error
 
 	ListAllDomainsKeys(opts *ListAllDomainsKeysOptions) *AllDomainsKeysIterator
+	AllDomainsKeys(ctx context.Context, opts *ListAllDomainsKeysOptions) iter.Seq2[mtypes.DomainKey, error]
 	CreateDomainKey(ctx context.Context, domain, dkimSelector string, opts *CreateDomainKeyOptions) (mtypes.DomainKey, error)
 	DeleteDomainKey(ctx context.Context, domain, dkimSelector string) error
 	ActivateDomainKey(ctx context.Context, domain, dkimSelector string) error
 	ListDomainKeys(domain string) *DomainKeysIterator
+	AllDomainKeys(ctx context.Context, domain string) iter.Seq2[mtypes.DomainKey, error]
 	DeactivateDomainKey(ctx context.Context, domain, dkimSelector string) error
 	UpdateDomainDkimAuthority(ctx context.Context, domain string, self bool) (mtypes.UpdateDomainDkimAuthorityResponse, error)
 	UpdateDomainDkimSelector(ctx context.Context, domain, dkimSelector string) error
@@ -192,13 +212,19 @@ type Mailgun interface {
 	GetStoredMessage(ctx context.Context, url string) (mtypes.StoredMessage, error)
 	GetStoredMessageRaw(ctx context.Context, id string) (mtypes.StoredMessageRaw, error)
 	GetStoredAttachment(ctx context.Context, url string) ([]byte, error)
+	FetchStoredMessage(ctx context.Context, url string) (*mtypes.MIMEMessage, error)
+	DeleteStoredMessage(ctx context.Context, url string) error
 
 	ListCredentials(domain string, opts *ListOptions) *CredentialsIterator
+	AllCredentials(ctx context.Context, domain string, opts *ListOptions) iter.Seq2[mtypes.Credential, error]
+	ResumeCredentials(cursor string) (*CredentialsIterator, error)
 	CreateCredential(ctx context.Context, domain, login, password string) error
 	ChangeCredentialPassword(ctx context.Context, domain, login, password string) error
 	DeleteCredential(ctx context.Context, domain, login string) error
 
 	ListUnsubscribes(domain string, opts *ListOptions) *UnsubscribesIterator
+	AllUnsubscribes(ctx context.Context, domain string, opts *ListOptions) iter.Seq2[mtypes.Unsubscribe, error]
+	ResumeUnsubscribes(cursor string) (*UnsubscribesIterator, error)
 	GetUnsubscribe(ctx context.Context, domain, address string) (mtypes.Unsubscribe, error)
 	CreateUnsubscribe(ctx context.Context, domain, address, tag string) error
 	CreateUnsubscribes(ctx context.Context, domain string, unsubscribes []mtypes.Unsubscribe) error
@@ -206,12 +232,16 @@ type Mailgun interface {
 	DeleteUnsubscribeWithTag(ctx context.Context, domain, address, tag string) error
 
 	ListComplaints(domain string, opts *ListOptions) *ComplaintsIterator
+	AllComplaints(ctx context.Context, domain string, opts *ListOptions) iter.Seq2[mtypes.Complaint, error]
+	ResumeComplaints(cursor string) (*ComplaintsIterator, error)
 	GetComplaint(ctx context.Context, domain, address string) (mtypes.Complaint, error)
 	CreateComplaint(ctx context.Context, domain, address string) error
 	CreateComplaints(ctx context.Context, domain string, addresses []string) error
 	DeleteComplaint(ctx context.Context, domain, address string) error
 
 	ListRoutes(opts *ListOptions) *RoutesIterator
+	AllRoutes(ctx context.Context, opts *ListOptions) iter.Seq2[mtypes.Route, error]
+	ResumeRoutes(cursor string) (*RoutesIterator, error)
 	GetRoute(ctx context.Context, id string) (mtypes.Route, error)
 	CreateRoute(ctx context.Context, route mtypes.Route) (mtypes.Route, error)
 	DeleteRoute(ctx context.Context, id string) error
@@ -223,14 +253,25 @@ type Mailgun interface {
 	GetWebhook(ctx context.Context, domain, kind string) ([]string, error)
 	UpdateWebhook(ctx context.Context, domain, kind string, url []string) error
 	VerifyWebhookSignature(sig mtypes.Signature) (verified bool, err error)
+	GetWebhookSigningKey(ctx context.Context) (mtypes.WebhookSigningKeyResponse, error)
+	RegenerateWebhookSigningKey(ctx context.Context) (mtypes.WebhookSigningKeyResponse, error)
+	RotateWebhookSigningKey(ctx context.Context, verifiers ...*webhooks.Verifier) (string, error)
+	ReconcileWebhooks(ctx context.Context, domain string, desired map[string][]string,
+		opts *ReconcileWebhooksOptions) (*WebhookPlan, error)
+	ReconcileWebhooksForDomains(ctx context.Context, targets []WebhookTarget, desired map[string][]string,
+		opts *ReconcileWebhooksOptions) ([]WebhookPlan, error)
 
 	ListMailingLists(opts *ListOptions) *ListsIterator
+	AllMailingLists(ctx context.Context, opts *ListOptions) iter.Seq2[mtypes.MailingList, error]
+	ResumeMailingLists(cursor string) (*ListsIterator, error)
 	CreateMailingList(ctx context.Context, ml mtypes.MailingList) (mtypes.MailingList, error)
 	DeleteMailingList(ctx context.Context, address string) error
 	GetMailingList(ctx context.Context, address string) (mtypes.MailingList, error)
 	UpdateMailingList(ctx context.Context, address string, ml mtypes.MailingList) (mtypes.MailingList, error)
 
 	ListMembers(listAddress string, opts *ListOptions) *MemberListIterator
+	AllMembers(ctx context.Context, listAddress string, opts *ListOptions) iter.Seq2[mtypes.Member, error]
+	ResumeMembers(cursor string) (*MemberListIterator, error)
 	GetMember(ctx context.Context, memberAddress, listAddress string) (mtypes.Member, error)
 	CreateMember(ctx context.Context, merge bool, listAddress string, member mtypes.Member) error
 	CreateMemberList(ctx context.Context, subscribed *bool, listAddress string, newMembers []any) error
@@ -238,7 +279,13 @@ type Mailgun interface {
 	DeleteMember(ctx context.Context, memberAddress, listAddress string) error
 
 	ListEvents(domain string, opts *ListEventOptions) *EventIterator
+	AllEvents(ctx context.Context, domain string, opts *ListEventOptions) iter.Seq2[events.Event, error]
+	ResumeEvents(cursor string) (*EventIterator, error)
+	GetMessageTimeline(ctx context.Context, domain, messageID string, opts *MessageTimelineOptions) (*MessageTimeline, error)
+	GetMessageTimelineByStorageKey(ctx context.Context, domain, storageKey string, opts *MessageTimelineOptions) (*MessageTimeline, error)
 	PollEvents(domain string, opts *ListEventOptions) *EventPoller
+	Subscribe(ctx context.Context, domains []string, filter *EventFilter, opts *SubscribeOptions) *Subscription
+	ExportEvents(ctx context.Context, domain string, w io.Writer, opts *EventExportOptions) (int, error)
 
 	ListIPs(ctx context.Context, dedicated, enabled bool) ([]mtypes.IPAddress, error)
 	GetIP(ctx context.Context, ip string) (mtypes.IPAddress, error)
@@ -247,6 +294,8 @@ type Mailgun interface {
 	DeleteDomainIP(ctx context.Context, domain, ip string) error
 
 	ListIPWarmups() *IPWarmupsIterator
+	AllIPWarmups(ctx context.Context) iter.Seq2[mtypes.IPWarmup, error]
+	ResumeIPWarmups(cursor string) (*IPWarmupsIterator, error)
 	GetIPWarmup(ctx context.Context, ip string) (mtypes.IPWarmupDetails, error)
 	CreateIPWarmup(ctx context.Context, ip string) error
 	DeleteIPWarmup(ctx context.Context, ip string) error
@@ -263,12 +312,16 @@ type Mailgun interface {
 	UpdateTemplate(ctx context.Context, domain string, template *mtypes.Template) error
 	DeleteTemplate(ctx context.Context, domain, name string) error
 	ListTemplates(domain string, opts *ListTemplateOptions) *TemplatesIterator
+	AllTemplates(ctx context.Context, domain string, opts *ListTemplateOptions) iter.Seq2[mtypes.Template, error]
+	ResumeTemplates(cursor string) (*TemplatesIterator, error)
 
 	AddTemplateVersion(ctx context.Context, domain, templateName string, version *mtypes.TemplateVersion) error
 	GetTemplateVersion(ctx context.Context, domain, templateName, tag string) (mtypes.TemplateVersion, error)
 	UpdateTemplateVersion(ctx context.Context, domain, templateName string, version *mtypes.TemplateVersion) error
 	DeleteTemplateVersion(ctx context.Context, domain, templateName, tag string) error
 	ListTemplateVersions(domain, templateName string, opts *ListOptions) *TemplateVersionsIterator
+	AllTemplateVersions(ctx context.Context, domain, templateName string, opts *ListOptions) iter.Seq2[mtypes.TemplateVersion, error]
+	ResumeTemplateVersions(cursor string) (*TemplateVersionsIterator, error)
 
 	ValidateEmail(ctx context.Context, email string, mailBoxVerify bool) (mtypes.ValidateEmailResponse, error)
 
@@ -278,10 +331,12 @@ type Mailgun interface {
 	DeleteAlert(ctx context.Context, id uuid.UUID) error
 
 	ListMonitoredDomains(opts ListMonitoredDomainsOptions) (*MonitoredDomainsIterator, error)
+	AllMonitoredDomains(ctx context.Context, opts ListMonitoredDomainsOptions) iter.Seq2[mtypes.MonitoredDomain, error]
 
 	CreateInboxPlacementTest(ctx context.Context, opts mtypes.CreateInboxPlacementTestOptions) (*mtypes.CreateInboxPlacementTestResponse, error)
 
 	ListSubaccounts(opts *ListSubaccountsOptions) *SubaccountsIterator
+	AllSubaccounts(ctx context.Context, opts *ListSubaccountsOptions) iter.Seq2[mtypes.Subaccount, error]
 	CreateSubaccount(ctx context.Context, subaccountName string) (mtypes.SubaccountResponse, error)
 	GetSubaccount(ctx context.Context, subaccountID string) (mtypes.SubaccountResponse, error)
 	EnableSubaccount(ctx context.Context, subaccountID string) (mtypes.SubaccountResponse, error)
@@ -293,11 +348,12 @@ type Mailgun interface {
 
 // Client bundles data needed by a large number of methods in order to interact with the Mailgun API.
 type Client struct {
-	apiBase           string
-	apiKey            string
-	webhookSigningKey string
-	client            *http.Client
-	overrideHeaders   map[string]string
+	apiBase         string
+	apiKey          string
+	webhookVerifier *webhooks.Verifier
+	client          *http.Client
+	overrideHeaders map[string]string
+	sandbox         *SandboxOptions
 }
 
 // NewMailgun creates a new client instance.
@@ -310,7 +366,8 @@ func NewMailgun(apiKey string) *Client {
 }
 
 // NewMailgunFromEnv returns a new Mailgun client using the environment variables
-// MG_API_KEY, MG_URL, and MG_WEBHOOK_SIGNING_KEY
+// MG_API_KEY, MG_URL, MG_WEBHOOK_SIGNING_KEY and MG_SANDBOX_CATCH_ALL.
+// If MG_SANDBOX_CATCH_ALL is set, the client redirects all recipients to this address.
 func NewMailgunFromEnv() (*Client, error) {
 	apiKey := os.Getenv("MG_API_KEY")
 	if apiKey == "" {
@@ -332,6 +389,11 @@ func NewMailgunFromEnv() (*Client, error) {
 		mg.SetWebhookSigningKey(webhookSigningKey)
 	}
 
+	catchAll := os.Getenv("MG_SANDBOX_CATCH_ALL")
+	if catchAll != "" {
+		mg.SetSandbox(&SandboxOptions{CatchAll: catchAll})
+	}
+
 	return mg, nil
 }
 
@@ -357,12 +419,50 @@ func (mg *Client) SetHTTPClient(c *http.Client) {
 
 // WebhookSigningKey returns the webhook signing key configured for this client
 func (mg *Client) WebhookSigningKey() string {
-	return mg.webhookSigningKey
+	if mg.webhookVerifier == nil {
+		return ""
+	}
+	return mg.webhookVerifier.SigningKey()
 }
 
 // SetWebhookSigningKey updates the webhook signing key for this client
 func (mg *Client) SetWebhookSigningKey(webhookSigningKey string) {
-	mg.webhookSigningKey = webhookSigningKey
+	mg.SetWebhookSigningKeys(webhookSigningKey)
+}
+
+// WebhookSigningKeys returns the webhook signing keys configured for this client, current key first.
+func (mg *Client) WebhookSigningKeys() []string {
+	if mg.webhookVerifier == nil {
+		return nil
+	}
+	return append([]string{mg.webhookVerifier.SigningKey()}, mg.webhookVerifier.PreviousSigningKeys()...)
+}
+
+// SetWebhookSigningKeys updates the webhook signing keys for this client: the current key first,
+// then the previous keys still accepted by VerifyWebhookSignature during a rotation.
+// The key matches are reset, see WebhookKeyMatches.
+func (mg *Client) SetWebhookSigningKeys(keys ...string) {
+	keys = slices.DeleteFunc(slices.Clone(keys), func(key string) bool {
+		return key == ""
+	})
+	if len(keys) == 0 {
+		mg.webhookVerifier = nil
+		return
+	}
+	// VerifyWebhookSignature only checks the signature, not the timestamp and the token.
+	mg.webhookVerifier = webhooks.NewVerifier(keys[0], keys[1:]...)
+	mg.webhookVerifier.Tolerance = 0
+	mg.webhookVerifier.Tokens = nil
+}
+
+// WebhookKeyMatches returns the number of signatures verified by VerifyWebhookSignature with each
+// webhook signing key since they were set or rotated, indexed as in WebhookSigningKeys, e.g. to check
+// that the previous keys no longer match before retiring them.
+func (mg *Client) WebhookKeyMatches() []uint64 {
+	if mg.webhookVerifier == nil {
+		return nil
+	}
+	return mg.webhookVerifier.KeyMatches()
 }
 
 // SetOnBehalfOfSubaccount sets X-Mailgun-On-Behalf-Of header to SUBACCOUNT_ACCOUNT_ID in order to perform API request
@@ -404,6 +504,13 @@ func (mg *Client) AddOverrideHeader(k, v string) {
 	mg.overrideHeaders[k] = v
 }
 
+// setOverrideHeaders adds the override headers to the request.
+func (mg *Client) setOverrideHeaders(r *httpRequest) {
+	for k, v := range mg.overrideHeaders {
+		r.addHeader(k, v)
+	}
+}
+
 // ListOptions used by List methods to specify what list parameters to send to the mailgun API
 type ListOptions struct {
 	Limit int
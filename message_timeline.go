@@ -0,0 +1,245 @@
+package mailgun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+)
+
+// MessageState is the state of a message for a recipient, computed from its events.
+type MessageState string
+
+const (
+	MessageStateUnknown      MessageState = ""
+	MessageStateRejected     MessageState = "rejected"
+	MessageStateAccepted     MessageState = "accepted"
+	MessageStateDeferred     MessageState = "deferred"
+	MessageStateDelivered    MessageState = "delivered"
+	MessageStateFailed       MessageState = "failed"
+	MessageStateOpened       MessageState = "opened"
+	MessageStateClicked      MessageState = "clicked"
+	MessageStateUnsubscribed MessageState = "unsubscribed"
+	MessageStateComplained   MessageState = "complained"
+)
+
+// messageStateRanks orders the states, the final state of a recipient is the highest-ranked one.
+var messageStateRanks = map[MessageState]int{
+	MessageStateUnknown:      0,
+	MessageStateRejected:     1,
+	MessageStateAccepted:     2,
+	MessageStateDeferred:     3,
+	MessageStateDelivered:    4,
+	MessageStateFailed:       5,
+	MessageStateOpened:       6,
+	MessageStateClicked:      7,
+	MessageStateUnsubscribed: 8,
+	MessageStateComplained:   9,
+}
+
+// MessageTimeline is the history of a message, reconstructed from its events.
+type MessageTimeline struct {
+	MessageID string
+	// Events are all the events of the message in chronological order,
+	// including the events without a recipient, e.g. stored.
+	Events []events.Event
+	// Recipients are the timelines of each recipient, sorted by recipient.
+	Recipients []RecipientTimeline
+}
+
+// Recipient returns the timeline of the recipient, or nil if the message has no event for the recipient.
+func (t *MessageTimeline) Recipient(recipient string) *RecipientTimeline {
+	for i := range t.Recipients {
+		if strings.EqualFold(t.Recipients[i].Recipient, recipient) {
+			return &t.Recipients[i]
+		}
+	}
+	return nil
+}
+
+// RecipientTimeline is the history of a message for one recipient.
+type RecipientTimeline struct {
+	Recipient string
+	// Events are the events of the recipient in chronological order.
+	Events []events.Event
+	// Attempts are the delivery attempts of delivered and failed events in chronological order.
+	Attempts []events.DeliveryStatus
+	// State is the final state of the message for the recipient. A permanent failure overrides a delivery
+	// (delayed bounce), and engagement (opened, clicked, unsubscribed, complained) overrides both.
+	State MessageState
+	// AcceptedAt is the time of the first accepted event.
+	AcceptedAt time.Time
+	// CompletedAt is the time of the delivery, or of the permanent failure.
+	CompletedAt time.Time
+	// Latency is the time between AcceptedAt and CompletedAt, zero if either is unknown.
+	Latency time.Duration
+}
+
+// MessageTimelineOptions modifies the behavior of GetMessageTimeline().
+type MessageTimelineOptions struct {
+	// Limits the events to a specific start and end time.
+	Begin, End time.Time
+}
+
+// GetMessageTimeline fetches all the events of the message across pages and returns its
+// per-recipient timeline. The message ID can be given with or without angle brackets.
+func (mg *Client) GetMessageTimeline(ctx context.Context, domain, messageID string, opts *MessageTimelineOptions) (*MessageTimeline, error) {
+	messageID = strings.Trim(strings.TrimSpace(messageID), "<>")
+	if messageID == "" {
+		return nil, errors.New("message ID is required")
+	}
+
+	listOpts := ListEventOptions{
+		ForceAscending: true,
+		Limit:          300,
+		FilterBy:       NewEventFilter().MessageID(FilterEq(messageID)),
+	}
+	if opts != nil {
+		listOpts.Begin = opts.Begin
+		listOpts.End = opts.End
+	}
+
+	var all []events.Event
+	var page []events.Event
+	it := mg.ListEvents(domain, &listOpts)
+	for it.Next(ctx, &page) {
+		all = append(all, page...)
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("while listing events of message '%s': %w", messageID, err)
+	}
+
+	return NewMessageTimeline(messageID, all), nil
+}
+
+// GetMessageTimelineByStorageKey returns the timeline of a stored message, see GetMessageTimeline.
+// The message ID is read from the headers of the stored message.
+func (mg *Client) GetMessageTimelineByStorageKey(ctx context.Context, domain, storageKey string, opts *MessageTimelineOptions) (*MessageTimeline, error) {
+	url := generateV3DomainsApiUrl(mg, messagesEndpoint, domain) + "/" + storageKey
+	stored, err := mg.GetStoredMessage(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("while getting stored message '%s': %w", storageKey, err)
+	}
+
+	for _, header := range stored.MessageHeaders {
+		if len(header) == 2 && textproto.CanonicalMIMEHeaderKey(header[0]) == "Message-Id" {
+			return mg.GetMessageTimeline(ctx, domain, header[1], opts)
+		}
+	}
+	return nil, fmt.Errorf("stored message '%s' has no Message-Id header", storageKey)
+}
+
+// NewMessageTimeline builds the timeline of a message from its events, e.g. events already fetched
+// with the `message-id` filter or received by webhooks. The events are sorted chronologically.
+func NewMessageTimeline(messageID string, ee []events.Event) *MessageTimeline {
+	t := MessageTimeline{
+		MessageID: messageID,
+		Events:    slices.Clone(ee),
+	}
+	sort.SliceStable(t.Events, func(i, j int) bool {
+		return t.Events[i].GetTimestamp().Before(t.Events[j].GetTimestamp())
+	})
+
+	byRecipient := make(map[string]*RecipientTimeline)
+	for _, e := range t.Events {
+		for _, rcpt := range eventRecipients(e) {
+			key := strings.ToLower(rcpt)
+			rt, ok := byRecipient[key]
+			if !ok {
+				rt = &RecipientTimeline{Recipient: rcpt}
+				byRecipient[key] = rt
+			}
+			rt.add(e)
+		}
+	}
+
+	for _, rt := range byRecipient {
+		if !rt.AcceptedAt.IsZero() && !rt.CompletedAt.IsZero() {
+			rt.Latency = rt.CompletedAt.Sub(rt.AcceptedAt)
+		}
+		t.Recipients = append(t.Recipients, *rt)
+	}
+	sort.Slice(t.Recipients, func(i, j int) bool {
+		return t.Recipients[i].Recipient < t.Recipients[j].Recipient
+	})
+	return &t
+}
+
+// add records the event, which must be more recent than the events already added.
+func (rt *RecipientTimeline) add(e events.Event) {
+	rt.Events = append(rt.Events, e)
+
+	var state MessageState
+	switch event := e.(type) {
+	case *events.Rejected:
+		state = MessageStateRejected
+	case *events.Accepted:
+		state = MessageStateAccepted
+		if rt.AcceptedAt.IsZero() {
+			rt.AcceptedAt = event.GetTimestamp()
+		}
+	case *events.Delivered:
+		state = MessageStateDelivered
+		rt.Attempts = append(rt.Attempts, event.DeliveryStatus)
+		if rt.CompletedAt.IsZero() {
+			rt.CompletedAt = event.GetTimestamp()
+		}
+	case *events.Failed:
+		rt.Attempts = append(rt.Attempts, event.DeliveryStatus)
+		state = MessageStateDeferred
+		if event.Severity == events.SeverityPermanent {
+			state = MessageStateFailed
+			rt.CompletedAt = event.GetTimestamp()
+		}
+	case *events.Dropped:
+		state = MessageStateFailed
+		rt.CompletedAt = event.GetTimestamp()
+	case *events.Opened:
+		state = MessageStateOpened
+	case *events.Clicked:
+		state = MessageStateClicked
+	case *events.Unsubscribed:
+		state = MessageStateUnsubscribed
+	case *events.Complained:
+		state = MessageStateComplained
+	}
+
+	if messageStateRanks[state] > messageStateRanks[rt.State] {
+		rt.State = state
+	}
+}
+
+// eventRecipients returns the recipients the event applies to.
+func eventRecipients(e events.Event) []string {
+	var rcpt string
+	switch event := e.(type) {
+	case *events.Accepted:
+		rcpt = event.Recipient
+	case *events.Delivered:
+		rcpt = event.Recipient
+	case *events.Failed:
+		rcpt = event.Recipient
+	case *events.Dropped:
+		rcpt = event.Recipient
+	case *events.Opened:
+		rcpt = event.Recipient
+	case *events.Clicked:
+		rcpt = event.Recipient
+	case *events.Unsubscribed:
+		rcpt = event.Recipient
+	case *events.Complained:
+		rcpt = event.Recipient
+	case *events.Rejected:
+		return event.Message.Recipients
+	}
+	if rcpt == "" {
+		return nil
+	}
+	return []string{rcpt}
+}
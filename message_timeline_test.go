@@ -0,0 +1,142 @@
+package mailgun_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTimelineEvents(base time.Time) []events.Event {
+	at := func(e events.Event, d time.Duration) events.Event {
+		e.SetTimestamp(base.Add(d))
+		e.SetID(d.String())
+		return e
+	}
+
+	opened := &events.Opened{Recipient: "joe@example.com"}
+	opened.Name = events.EventOpened
+	deferred := &events.Failed{Recipient: "jane@example.com", Severity: events.SeverityTemporary,
+		DeliveryStatus: events.DeliveryStatus{Code: 421, AttemptNo: 1}}
+	deferred.Name = events.EventFailed
+	delivered := &events.Delivered{Recipient: "joe@example.com", DeliveryStatus: events.DeliveryStatus{Code: 250, AttemptNo: 1}}
+	delivered.Name = events.EventDelivered
+	failed := &events.Failed{Recipient: "jane@example.com", Severity: events.SeverityPermanent,
+		DeliveryStatus: events.DeliveryStatus{Code: 550, AttemptNo: 2}}
+	failed.Name = events.EventFailed
+	accepted1 := &events.Accepted{Recipient: "joe@example.com"}
+	accepted1.Name = events.EventAccepted
+	accepted2 := &events.Accepted{Recipient: "jane@example.com"}
+	accepted2.Name = events.EventAccepted
+
+	// Out of order, as returned by several pages.
+	return []events.Event{
+		at(opened, 10*time.Minute),
+		at(deferred, 2*time.Second),
+		at(delivered, 3*time.Second),
+		at(failed, 5*time.Minute),
+		at(accepted1, 0),
+		at(accepted2, time.Second),
+	}
+}
+
+func TestNewMessageTimeline(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tl := mailgun.NewMessageTimeline("id@example.com", newTimelineEvents(base))
+
+	require.Len(t, tl.Events, 6)
+	assert.Equal(t, events.EventAccepted, tl.Events[0].GetName())
+	assert.Equal(t, events.EventOpened, tl.Events[5].GetName())
+	require.Len(t, tl.Recipients, 2)
+
+	joe := tl.Recipient("Joe@example.com")
+	require.NotNil(t, joe)
+	assert.Equal(t, mailgun.MessageStateOpened, joe.State)
+	assert.Len(t, joe.Events, 3)
+	assert.Equal(t, base, joe.AcceptedAt)
+	assert.Equal(t, 3*time.Second, joe.Latency)
+	assert.Equal(t, []events.DeliveryStatus{{Code: 250, AttemptNo: 1}}, joe.Attempts)
+
+	jane := tl.Recipient("jane@example.com")
+	require.NotNil(t, jane)
+	assert.Equal(t, mailgun.MessageStateFailed, jane.State)
+	assert.Equal(t, 5*time.Minute-time.Second, jane.Latency)
+	require.Len(t, jane.Attempts, 2)
+	assert.Equal(t, 421, jane.Attempts[0].Code)
+	assert.Equal(t, 550, jane.Attempts[1].Code)
+
+	assert.Nil(t, tl.Recipient("bob@example.com"))
+}
+
+func TestNewMessageTimelineDropped(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	accepted := &events.Accepted{Recipient: "gone@example.com"}
+	accepted.Name = events.EventAccepted
+	accepted.SetTimestamp(base)
+	dropped := &events.Dropped{Recipient: "gone@example.com", Reason: events.ReasonSuppressBounce}
+	dropped.Name = events.EventDropped
+	dropped.SetTimestamp(base.Add(time.Second))
+
+	tl := mailgun.NewMessageTimeline("id@example.com", []events.Event{dropped, accepted})
+	gone := tl.Recipient("gone@example.com")
+	require.NotNil(t, gone)
+	assert.Equal(t, mailgun.MessageStateFailed, gone.State)
+	assert.Equal(t, time.Second, gone.Latency)
+}
+
+func TestGetMessageTimeline(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	ee := newTimelineEvents(base)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/domains/" + testDomain + "/messages/storage-key":
+			_, _ = w.Write([]byte(`{"message-headers": [["Subject", "Hi"], ["Message-Id", "<id@example.com>"]]}`))
+			return
+		case "/v3/" + testDomain + "/events":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		assert.Equal(t, "id@example.com", r.FormValue("message-id"))
+		resp := map[string]any{"items": []events.Event{}}
+		switch r.FormValue("page") {
+		case "":
+			resp = map[string]any{"items": ee[:3], "paging": map[string]string{
+				"next": srv.URL + r.URL.Path + "?message-id=id@example.com&page=2",
+			}}
+		case "2":
+			resp = map[string]any{"items": ee[3:], "paging": map[string]string{
+				"next": srv.URL + r.URL.Path + "?message-id=id@example.com&page=3",
+			}}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+	ctx := context.Background()
+
+	tl, err := mg.GetMessageTimeline(ctx, testDomain, "<id@example.com>", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "id@example.com", tl.MessageID)
+	assert.Len(t, tl.Events, 6)
+	assert.Len(t, tl.Recipients, 2)
+
+	tl, err = mg.GetMessageTimelineByStorageKey(ctx, testDomain, "storage-key", nil)
+	require.NoError(t, err)
+	assert.Len(t, tl.Events, 6)
+
+	_, err = mg.GetMessageTimelineByStorageKey(ctx, testDomain, "missing", nil)
+	require.Error(t, err)
+}
@@ -190,6 +190,9 @@ type Specific interface {
 //
 // Note that you'll need to invoke the AddRecipientAndVariables or AddRecipient method
 // before sending, though.
+//
+// Use AddRecipients, AddCCRecipients and AddBCCRecipients to add typed and normalized
+// recipients, and NormalizeRecipients to normalize the ones given as strings.
 func NewMessage(domain, from, subject, text string, to ...string) *PlainMessage {
 	return &PlainMessage{
 		CommonMessage: CommonMessage{
@@ -733,13 +736,17 @@ func (mg *Client) Send(ctx context.Context, m Message) (mtypes.SendMessageRespon
 		return response, err
 	}
 
+	if mg.sandbox != nil {
+		err = mg.sandbox.apply(payload)
+		if err != nil {
+			return response, err
+		}
+	}
+
 	r := newHTTPRequest(generateApiV3UrlWithDomain(mg, m.Endpoint(), m.Domain()))
 	r.setClient(mg.HTTPClient())
 	r.setBasicAuth(basicAuthUser, mg.APIKey())
-	// Override any HTTP headers if provided
-	for k, v := range mg.overrideHeaders {
-		r.addHeader(k, v)
-	}
+	mg.setOverrideHeaders(r)
 
 	err = postResponseFromJSON(ctx, r, payload, &response)
 
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"net/mail"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -44,6 +45,8 @@ type Server struct {
 	tags             []mtypes.Tag
 	subaccountList   []mtypes.Subaccount
 	webhooks         mtypes.WebHooksListResponse
+	signingKey       string
+	scheduled        map[string][]string
 	mutex            sync.Mutex
 	apiKeysList      []mtypes.APIKey
 }
@@ -96,6 +99,13 @@ func (ms *Server) Webhooks() mtypes.WebHooksListResponse {
 	return ms.webhooks
 }
 
+// ScheduledMessages returns the IDs of the messages scheduled for future delivery on a domain.
+func (ms *Server) ScheduledMessages(domain string) []string {
+	defer ms.mutex.Unlock()
+	ms.mutex.Lock()
+	return slices.Clone(ms.scheduled[domain])
+}
+
 func (ms *Server) Templates() []mtypes.Template {
 	defer ms.mutex.Unlock()
 	ms.mutex.Lock()
@@ -114,6 +124,13 @@ func (ms *Server) SubaccountList() []mtypes.Subaccount {
 	return ms.subaccountList
 }
 
+// WebhookSigningKey returns the current HTTP webhook signing key of the account.
+func (ms *Server) WebhookSigningKey() string {
+	defer ms.mutex.Unlock()
+	ms.mutex.Lock()
+	return ms.signingKey
+}
+
 func (ms *Server) APIKeysList() []mtypes.APIKey {
 	defer ms.mutex.Unlock()
 	ms.mutex.Lock()
@@ -122,7 +139,9 @@ func (ms *Server) APIKeysList() []mtypes.APIKey {
 
 // NewServer creates a new instance of the mailgun API mock server
 func NewServer() *Server {
-	ms := Server{}
+	ms := Server{
+		scheduled: make(map[string][]string),
+	}
 
 	// Add all our handlers
 	r := chi.NewRouter()
@@ -137,6 +156,7 @@ func NewServer() *Server {
 		ms.addMailingListRoutes(r)
 		ms.addEventRoutes(r)
 		ms.addMessagesRoutes(r)
+		ms.addSendingQueuesRoutes(r)
 		ms.addRoutes(r)
 		ms.addWebhookRoutes(r)
 		ms.addTemplateRoutes(r)
@@ -150,6 +170,7 @@ func NewServer() *Server {
 	})
 	r.Route("/v5", func(r chi.Router) {
 		ms.addSubaccountRoutes(r)
+		ms.addWebhookSigningKeyRoutes(r)
 	})
 
 	// self-defined API version routes
@@ -18,6 +18,7 @@ func (ms *Server) addMessagesRoutes(r chi.Router) {
 	// This path is made up; it could be anything as the storage url could change over time
 	r.Get("/se.storage.url/messages/{id}", ms.getStoredMessages)
 	r.Post("/se.storage.url/messages/{id}", ms.sendStoredMessages)
+	r.Delete("/se.storage.url/messages/{id}", ms.deleteStoredMessages)
 }
 
 // TODO: This implementation doesn't support multiple recipients
@@ -30,6 +31,21 @@ func (ms *Server) createMessages(w http.ResponseWriter, r *http.Request) {
 	}
 	id := randomString(16, "ID-")
 
+	if deliveryTime := r.FormValue("o:deliverytime"); deliveryTime != "" {
+		t, err := time.Parse("Mon, 2 Jan 2006 15:04:05 -0700", deliveryTime)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			toJSON(w, okResp{Message: "invalid 'o:deliverytime'"})
+			return
+		}
+		if t.After(time.Now()) {
+			domain := chi.URLParam(r, "domain")
+			ms.mutex.Lock()
+			ms.scheduled[domain] = append(ms.scheduled[domain], id)
+			ms.mutex.Unlock()
+		}
+	}
+
 	switch to.Address {
 	case "stored@mailgun.test":
 		stored := new(events.Stored)
@@ -119,6 +135,23 @@ func (ms *Server) getStoredMessages(w http.ResponseWriter, r *http.Request) {
 	if stored == nil {
 		w.WriteHeader(http.StatusNotFound)
 		toJSON(w, okResp{Message: "not found"})
+		return
+	}
+
+	if r.Header.Get("Accept") == "message/rfc2822" {
+		toJSON(w, mtypes.StoredMessageRaw{
+			Recipients: strings.Join(stored.Message.Recipients, ","),
+			Sender:     stored.Message.Headers.From,
+			From:       stored.Message.Headers.From,
+			Subject:    stored.Message.Headers.Subject,
+			BodyMime: "From: " + stored.Message.Headers.From + "\r\n" +
+				"To: " + stored.Message.Headers.To + "\r\n" +
+				"Subject: " + stored.Message.Headers.Subject + "\r\n" +
+				"Message-Id: <" + stored.Message.Headers.MessageID + ">\r\n" +
+				"Content-Type: text/plain\r\n\r\n" +
+				stored.Message.Headers.Subject + "\r\n",
+		})
+		return
 	}
 
 	toJSON(w, mtypes.StoredMessage{
@@ -157,3 +190,20 @@ func (ms *Server) sendStoredMessages(w http.ResponseWriter, r *http.Request) {
 
 	toJSON(w, okResp{ID: "<" + id + ">", Message: "Queued. Thank you."})
 }
+
+func (ms *Server) deleteStoredMessages(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	defer ms.mutex.Unlock()
+	ms.mutex.Lock()
+
+	for i, event := range ms.events {
+		if _, ok := event.(*events.Stored); ok && event.GetID() == id {
+			ms.events = append(ms.events[:i], ms.events[i+1:]...)
+			toJSON(w, okResp{Message: "Message has been deleted"})
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+	toJSON(w, okResp{Message: "not found"})
+}
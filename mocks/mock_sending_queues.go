@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+func (ms *Server) addSendingQueuesRoutes(r chi.Router) {
+	r.Get("/domains/{domain}/sending_queues", ms.getSendingQueues)
+	r.Delete("/{domain}/envelopes", ms.deleteEnvelopes)
+}
+
+func (ms *Server) getSendingQueues(w http.ResponseWriter, _ *http.Request) {
+	toJSON(w, mtypes.DomainSendingQueues{})
+}
+
+func (ms *Server) deleteEnvelopes(w http.ResponseWriter, r *http.Request) {
+	defer ms.mutex.Unlock()
+	ms.mutex.Lock()
+
+	delete(ms.scheduled, chi.URLParam(r, "domain"))
+	toJSON(w, okResp{Message: "done"})
+}
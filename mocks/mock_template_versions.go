@@ -181,7 +181,7 @@ func (ms *Server) createTemplateVersion(w http.ResponseWriter, r *http.Request)
 		CreatedAt: mtypes.RFC2822Time(time.Now()),
 	}
 
-	if active == "yes" {
+	if stringToBool(active) {
 		newTemplateVersion.Active = true
 		for i, _ := range ms.templateVersions[templateName] {
 			ms.templateVersions[templateName][i].Active = false
@@ -245,7 +245,7 @@ func (ms *Server) updateTemplateVersion(w http.ResponseWriter, r *http.Request)
 		updated = true
 	}
 	if len(active) != 0 {
-		if active == "yes" {
+		if stringToBool(active) {
 			templateVersion.Active = true
 			for i := range ms.templateVersions[templateName] { // every other template version become not active
 				if i == templateVersionIndex {
@@ -149,7 +149,7 @@ func (ms *Server) getTemplate(w http.ResponseWriter, r *http.Request) {
 	active := r.Form.Get("active")
 	for _, template := range ms.templates {
 		if template.Name == templateName {
-			if active == "true" {
+			if stringToBool(active) {
 				version := ms.getActiveTemplateVersion(templateName)
 				if version.Active { // active version exists
 					template.Version = version
@@ -98,3 +98,28 @@ func (ms *Server) deleteWebHook(w http.ResponseWriter, r *http.Request) {
 	delete(ms.webhooks.Webhooks, chi.URLParam(r, "webhook"))
 	toJSON(w, okResp{Message: "success"})
 }
+
+func (ms *Server) addWebhookSigningKeyRoutes(r chi.Router) {
+	r.Get("/accounts/http_signing_key", ms.getWebhookSigningKey)
+	r.Post("/accounts/http_signing_key", ms.regenerateWebhookSigningKey)
+
+	ms.signingKey = "WEBHOOK_SIGNING_KEY"
+}
+
+func (ms *Server) getWebhookSigningKey(w http.ResponseWriter, _ *http.Request) {
+	defer ms.mutex.Unlock()
+	ms.mutex.Lock()
+
+	toJSON(w, mtypes.WebhookSigningKeyResponse{SigningKey: ms.signingKey})
+}
+
+func (ms *Server) regenerateWebhookSigningKey(w http.ResponseWriter, _ *http.Request) {
+	defer ms.mutex.Unlock()
+	ms.mutex.Lock()
+
+	ms.signingKey = randomString(32, "key-")
+	toJSON(w, mtypes.WebhookSigningKeyResponse{
+		Message:    "Webhook signing key has been regenerated",
+		SigningKey: ms.signingKey,
+	})
+}
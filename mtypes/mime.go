@@ -0,0 +1,72 @@
+package mtypes
+
+import (
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/mailgun/mailgun-go/v5/internal/msgid"
+)
+
+// MIMEPart is a leaf part of a MIME message, with its decoded content.
+type MIMEPart struct {
+	Header textproto.MIMEHeader
+	// ContentType is the media type of the part, e.g. "text/plain", and Params its parameters, e.g. the charset.
+	ContentType string
+	Params      map[string]string
+	// Disposition is "inline", "attachment", or "" when the part has no Content-Disposition header.
+	Disposition string
+	Filename    string
+	// ContentID is the Content-ID of the part, without the angle brackets.
+	ContentID string
+	// Body is the content of the part, with its transfer encoding decoded.
+	Body []byte
+}
+
+// IsAttachment reports whether the part is an attachment or an inline file rather than a body of the message.
+func (p *MIMEPart) IsAttachment() bool {
+	switch {
+	case p.Disposition == "attachment" || p.Filename != "":
+		return true
+	case p.ContentType == "text/plain" || p.ContentType == "text/html":
+		return false
+	default:
+		return p.ContentID != "" || p.ContentType == "message/rfc822"
+	}
+}
+
+// MIMEMessage is a parsed MIME message, e.g. a stored message, see webhooks.ParseMIME.
+type MIMEMessage struct {
+	Header mail.Header
+	// Parts are the leaf parts of the message, in order.
+	Parts []*MIMEPart
+	// Text and HTML are the first text/plain and text/html bodies of the message.
+	Text string
+	HTML string
+	// Attachments are the parts that are attachments or inline files, see MIMEPart.IsAttachment.
+	Attachments []*MIMEPart
+}
+
+// ContentID returns the part with the Content-ID, e.g. a key of the content-id-map of an inbound
+// message such as "<logo>", or the "cid:logo" source of an HTML image. It returns nil if there is none.
+func (m *MIMEMessage) ContentID(id string) *MIMEPart {
+	id = msgid.Trim(strings.TrimPrefix(id, "cid:"))
+	for _, p := range m.Parts {
+		if p.ContentID != "" && p.ContentID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// ResolveContentIDs maps each key of a content-id-map, e.g. webhooks.InboundMessage.ContentIDMap, to the part with
+// its Content-ID. Keys without a part are omitted.
+func (m *MIMEMessage) ResolveContentIDs(contentIDMap map[string]string) map[string]*MIMEPart {
+	parts := make(map[string]*MIMEPart, len(contentIDMap))
+	for id := range contentIDMap {
+		if p := m.ContentID(id); p != nil {
+			parts[id] = p
+		}
+	}
+	return parts
+}
@@ -0,0 +1,20 @@
+package mtypes
+
+// DomainSendingQueues is the status of the sending queues of a domain.
+type DomainSendingQueues struct {
+	// Regular is the queue of the messages sent for immediate delivery.
+	Regular SendingQueue `json:"regular"`
+	// Scheduled is the queue of the messages scheduled with o:deliverytime or o:deliverytime-optimize-period.
+	Scheduled SendingQueue `json:"scheduled"`
+}
+
+type SendingQueue struct {
+	IsDisabled bool                 `json:"is_disabled"`
+	Disabled   SendingQueueDisabled `json:"disabled"`
+}
+
+type SendingQueueDisabled struct {
+	// Until is empty unless the queue is disabled, e.g. 'Thu, 13 Oct 2011 18:02:00 UTC'.
+	Until  string `json:"until"`
+	Reason string `json:"reason"`
+}
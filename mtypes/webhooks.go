@@ -29,3 +29,9 @@ type WebhookPayload struct {
 	Signature Signature      `json:"signature"`
 	EventData events.RawJSON `json:"event-data"`
 }
+
+// WebhookSigningKeyResponse is the HTTP webhook signing key of the account.
+type WebhookSigningKeyResponse struct {
+	Message    string `json:"message"`
+	SigningKey string `json:"http_signing_key"`
+}
@@ -0,0 +1,301 @@
+package mailgun
+
+import (
+	"context"
+	"iter"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// pager fetches the next page of a list and reports whether it is the last one.
+type pager[T any] func(ctx context.Context) (items []T, last bool, err error)
+
+// allPages returns a sequence of the items of all the pages, stopping after the last or an empty page.
+// On error, including err of the iterator creation, the error is yielded with the zero value
+// and the sequence stops.
+func allPages[T any](ctx context.Context, err error, next pager[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		for {
+			items, last, err := next(ctx)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if last || len(items) == 0 {
+				return
+			}
+		}
+	}
+}
+
+// byURL pages a list by the URL of the next page, starting at first. fetch returns the items
+// of the page at url and the URL of the next one.
+func byURL[T any](first string, fetch func(ctx context.Context, url string) ([]T, string, error)) pager[T] {
+	next := first
+	return func(ctx context.Context) ([]T, bool, error) {
+		url := next
+		items, n, err := fetch(ctx, url)
+		if err != nil {
+			return nil, true, err
+		}
+		next = n
+		return items, next == "" || next == url, nil
+	}
+}
+
+// bySkip pages a list by skip and limit, starting at offset. A page shorter than limit is the last one.
+func bySkip[T any](offset, limit int, fetch func(ctx context.Context, skip, limit int) ([]T, error)) pager[T] {
+	return func(ctx context.Context) ([]T, bool, error) {
+		items, err := fetch(ctx, offset, limit)
+		if err != nil {
+			return nil, true, err
+		}
+		offset += len(items)
+		return items, limit > 0 && len(items) < limit, nil
+	}
+}
+
+// AllBounces returns a sequence of all the bounces of the domain.
+//
+//	for bounce, err := range mg.AllBounces(ctx, "example.com", nil) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(bounce.Address)
+//	}
+func (mg *Client) AllBounces(ctx context.Context, domain string, opts *ListOptions) iter.Seq2[mtypes.Bounce, error] {
+	it := mg.ListBounces(domain, opts)
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]mtypes.Bounce, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Items, it.Paging.Next, nil
+	}))
+}
+
+// AllUnsubscribes returns a sequence of all the unsubscribes of the domain.
+func (mg *Client) AllUnsubscribes(ctx context.Context, domain string, opts *ListOptions) iter.Seq2[mtypes.Unsubscribe, error] {
+	it := mg.ListUnsubscribes(domain, opts)
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]mtypes.Unsubscribe, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Items, it.Paging.Next, nil
+	}))
+}
+
+// AllComplaints returns a sequence of all the spam complaints of the domain.
+func (mg *Client) AllComplaints(ctx context.Context, domain string, opts *ListOptions) iter.Seq2[mtypes.Complaint, error] {
+	it := mg.ListComplaints(domain, opts)
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]mtypes.Complaint, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Items, it.Paging.Next, nil
+	}))
+}
+
+// AllTags returns a sequence of all the tags of the domain.
+func (mg *Client) AllTags(ctx context.Context, domain string, opts *ListTagOptions) iter.Seq2[mtypes.Tag, error] {
+	it := mg.ListTags(domain, opts)
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]mtypes.Tag, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Items, it.Paging.Next, nil
+	}))
+}
+
+// AllDomains returns a sequence of all the domains of the account.
+func (mg *Client) AllDomains(ctx context.Context, opts *ListDomainsOptions) iter.Seq2[mtypes.Domain, error] {
+	it := mg.ListDomains(opts)
+	return allPages(ctx, it.err, bySkip(it.offset, it.limit, func(ctx context.Context, skip, limit int) ([]mtypes.Domain, error) {
+		if err := it.fetch(ctx, skip, limit); err != nil {
+			return nil, err
+		}
+		return it.Items, nil
+	}))
+}
+
+// AllIPDomains returns a sequence of all the domains assigned to the IP.
+func (mg *Client) AllIPDomains(ctx context.Context, ip string, opts *ListIPDomainOptions) iter.Seq2[mtypes.DomainIPs, error] {
+	it := mg.ListIPDomains(ip, opts)
+	return allPages(ctx, it.err, bySkip(it.offset, it.limit, func(ctx context.Context, skip, limit int) ([]mtypes.DomainIPs, error) {
+		if err := it.fetch(ctx, skip, limit); err != nil {
+			return nil, err
+		}
+		return it.Items, nil
+	}))
+}
+
+// AllDomainsKeys returns a sequence of the DKIM keys of all the domains of the account.
+func (mg *Client) AllDomainsKeys(ctx context.Context, opts *ListAllDomainsKeysOptions) iter.Seq2[mtypes.DomainKey, error] {
+	it := mg.ListAllDomainsKeys(opts)
+	return allPages(ctx, it.err, byURL(it.url, func(ctx context.Context, url string) ([]mtypes.DomainKey, string, error) {
+		if err := it.fetch(ctx, url, it.limit); err != nil {
+			return nil, "", err
+		}
+		return it.Items, it.Paging.Next, nil
+	}))
+}
+
+// AllDomainKeys returns a sequence of all the DKIM keys of the domain.
+func (mg *Client) AllDomainKeys(ctx context.Context, domain string) iter.Seq2[mtypes.DomainKey, error] {
+	it := mg.ListDomainKeys(domain)
+	return allPages(ctx, it.err, byURL(it.uri, func(ctx context.Context, url string) ([]mtypes.DomainKey, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Items, it.Paging.Next, nil
+	}))
+}
+
+// AllCredentials returns a sequence of all the SMTP credentials of the domain.
+func (mg *Client) AllCredentials(ctx context.Context, domain string, opts *ListOptions) iter.Seq2[mtypes.Credential, error] {
+	it := mg.ListCredentials(domain, opts)
+	return allPages(ctx, it.err, bySkip(it.offset, it.limit, func(ctx context.Context, skip, limit int) ([]mtypes.Credential, error) {
+		if err := it.fetch(ctx, skip, limit); err != nil {
+			return nil, err
+		}
+		return it.Items, nil
+	}))
+}
+
+// AllRoutes returns a sequence of all the routes of the account.
+func (mg *Client) AllRoutes(ctx context.Context, opts *ListOptions) iter.Seq2[mtypes.Route, error] {
+	it := mg.ListRoutes(opts)
+	return allPages(ctx, it.err, bySkip(it.offset, it.limit, func(ctx context.Context, skip, limit int) ([]mtypes.Route, error) {
+		if err := it.fetch(ctx, skip, limit); err != nil {
+			return nil, err
+		}
+		return it.Items, nil
+	}))
+}
+
+// AllMailingLists returns a sequence of all the mailing lists of the account.
+func (mg *Client) AllMailingLists(ctx context.Context, opts *ListOptions) iter.Seq2[mtypes.MailingList, error] {
+	it := mg.ListMailingLists(opts)
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]mtypes.MailingList, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Items, it.Paging.Next, nil
+	}))
+}
+
+// AllMembers returns a sequence of all the members of the mailing list.
+func (mg *Client) AllMembers(ctx context.Context, listAddress string, opts *ListOptions) iter.Seq2[mtypes.Member, error] {
+	it := mg.ListMembers(listAddress, opts)
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]mtypes.Member, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Lists, it.Paging.Next, nil
+	}))
+}
+
+// AllEvents returns a sequence of all the events of the domain matching the options.
+func (mg *Client) AllEvents(ctx context.Context, domain string, opts *ListEventOptions) iter.Seq2[events.Event, error] {
+	it := mg.ListEvents(domain, opts)
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]events.Event, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		items, err := events.ParseEvents(it.Items)
+		return items, it.Paging.Next, err
+	}))
+}
+
+// AllIPWarmups returns a sequence of all the IP warmups of the account.
+func (mg *Client) AllIPWarmups(ctx context.Context) iter.Seq2[mtypes.IPWarmup, error] {
+	it := mg.ListIPWarmups()
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]mtypes.IPWarmup, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Items, it.Paging.Next, nil
+	}))
+}
+
+// AllTemplates returns a sequence of all the templates of the domain.
+func (mg *Client) AllTemplates(ctx context.Context, domain string, opts *ListTemplateOptions) iter.Seq2[mtypes.Template, error] {
+	it := mg.ListTemplates(domain, opts)
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]mtypes.Template, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Items, it.Paging.Next, nil
+	}))
+}
+
+// AllTemplateVersions returns a sequence of all the versions of the template.
+func (mg *Client) AllTemplateVersions(ctx context.Context, domain, templateName string, opts *ListOptions,
+) iter.Seq2[mtypes.TemplateVersion, error] {
+	it := mg.ListTemplateVersions(domain, templateName, opts)
+	return allPages(ctx, it.err, byURL(it.Paging.Next, func(ctx context.Context, url string) ([]mtypes.TemplateVersion, string, error) {
+		if err := it.fetch(ctx, url); err != nil {
+			return nil, "", err
+		}
+		return it.Template.Versions, it.Paging.Next, nil
+	}))
+}
+
+// AllSubaccounts returns a sequence of all the subaccounts of the account.
+func (mg *Client) AllSubaccounts(ctx context.Context, opts *ListSubaccountsOptions) iter.Seq2[mtypes.Subaccount, error] {
+	it := mg.ListSubaccounts(opts)
+	return allPages(ctx, it.err, bySkip(it.offset, it.limit, func(ctx context.Context, skip, limit int) ([]mtypes.Subaccount, error) {
+		if err := it.fetch(ctx, skip, limit); err != nil {
+			return nil, err
+		}
+		return it.Items, nil
+	}))
+}
+
+// AllMetrics returns a sequence of all the metrics items matching the options.
+func (mg *Client) AllMetrics(ctx context.Context, opts MetricsOptions) iter.Seq2[mtypes.MetricsItem, error] {
+	it, err := mg.ListMetrics(opts)
+	if err != nil {
+		return allPages[mtypes.MetricsItem](ctx, err, nil)
+	}
+	pagination := it.opts.Pagination
+	return allPages(ctx, it.err, bySkip(pagination.Skip, pagination.Limit,
+		func(ctx context.Context, skip, limit int) ([]mtypes.MetricsItem, error) {
+			it.opts.Pagination.Skip = skip
+			var resp mtypes.MetricsResponse
+			if err := it.fetch(ctx, &resp); err != nil {
+				return nil, err
+			}
+			return resp.Items, nil
+		}))
+}
+
+// AllMonitoredDomains returns a sequence of all the domains monitored by InboxReady.
+func (mg *Client) AllMonitoredDomains(ctx context.Context, opts ListMonitoredDomainsOptions) iter.Seq2[mtypes.MonitoredDomain, error] {
+	it, err := mg.ListMonitoredDomains(opts)
+	if err != nil {
+		return allPages[mtypes.MonitoredDomain](ctx, err, nil)
+	}
+	return allPages(ctx, it.err, byURL(it.req.URL, func(ctx context.Context, url string) ([]mtypes.MonitoredDomain, string, error) {
+		it.req.URL = url
+		resp, err := it.fetch(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		if resp.Paging == nil {
+			return resp.Items, "", nil
+		}
+		return resp.Items, resp.Paging.Next, nil
+	}))
+}
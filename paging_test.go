@@ -0,0 +1,119 @@
+package mailgun_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllBounces(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(server.URL()))
+	ctx := context.Background()
+
+	for _, address := range []string{"paging1@example.com", "paging2@example.com", "paging3@example.com"} {
+		require.NoError(t, mg.AddBounce(ctx, testDomain, address, "550", "No such mailbox"))
+	}
+
+	var want []mtypes.Bounce
+	var page []mtypes.Bounce
+	it := mg.ListBounces(testDomain, &mailgun.ListOptions{Limit: 2})
+	for it.Next(ctx, &page) {
+		want = append(want, page...)
+	}
+	require.NoError(t, it.Err())
+	require.NotEmpty(t, want)
+
+	var got []mtypes.Bounce
+	for b, err := range mg.AllBounces(ctx, testDomain, &mailgun.ListOptions{Limit: 2}) {
+		require.NoError(t, err)
+		got = append(got, b)
+	}
+	assert.Equal(t, want, got)
+
+	// Stopping early.
+	got = nil
+	for b, err := range mg.AllBounces(ctx, testDomain, &mailgun.ListOptions{Limit: 2}) {
+		require.NoError(t, err)
+		got = append(got, b)
+		break
+	}
+	assert.Len(t, got, 1)
+}
+
+func TestAllSkipLimitPaging(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(server.URL()))
+	ctx := context.Background()
+
+	var domains []string
+	for d, err := range mg.AllDomains(ctx, &mailgun.ListDomainsOptions{Limit: 1}) {
+		require.NoError(t, err)
+		domains = append(domains, d.Name)
+	}
+	assert.Contains(t, domains, testDomain)
+
+	var tags int
+	for _, err := range mg.AllTags(ctx, testDomain, &mailgun.ListTagOptions{Limit: 1}) {
+		require.NoError(t, err)
+		tags++
+	}
+	assert.NotZero(t, tags)
+
+	var events int
+	for _, err := range mg.AllEvents(ctx, testDomain, &mailgun.ListEventOptions{Limit: 5}) {
+		require.NoError(t, err)
+		events++
+	}
+	assert.NotZero(t, events)
+}
+
+func TestAllError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	var errs []error
+	for _, err := range mg.AllRoutes(context.Background(), nil) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.Equal(t, http.StatusForbidden, mailgun.GetStatusFromErr(errs[0]))
+}
+
+func TestAllOtherPaging(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(server.URL()))
+	ctx := context.Background()
+
+	var metrics int
+	for _, err := range mg.AllMetrics(ctx, mailgun.MetricsOptions{Metrics: []string{"sent_count"}}) {
+		require.NoError(t, err)
+		metrics++
+	}
+	assert.Equal(t, 1, metrics)
+
+	var keys int
+	for _, err := range mg.AllDomainKeys(ctx, testDomain) {
+		require.NoError(t, err)
+		keys++
+	}
+	assert.Equal(t, 2, keys)
+
+	var allKeys int
+	for _, err := range mg.AllDomainsKeys(ctx, &mailgun.ListAllDomainsKeysOptions{Limit: 1}) {
+		require.NoError(t, err)
+		allKeys++
+	}
+	assert.NotZero(t, allKeys)
+}
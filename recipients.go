@@ -2,7 +2,10 @@ package mailgun
 
 import (
 	"fmt"
+	"net/mail"
 	"strings"
+
+	"golang.org/x/net/idna"
 )
 
 type Recipient struct {
@@ -10,9 +13,50 @@ type Recipient struct {
 	Email string `json:"-"`
 }
 
+// ParseRecipient parses a single RFC 5322 address, e.g. "Joe <joe@example.com>" or "joe@example.com".
+func ParseRecipient(s string) (Recipient, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return Recipient{}, fmt.Errorf("malformed recipient string '%s': %w", s, err)
+	}
+	return Recipient{Name: addr.Name, Email: addr.Address}, nil
+}
+
+// ParseRecipientList parses a comma-separated list of RFC 5322 addresses.
+func ParseRecipientList(s string) ([]Recipient, error) {
+	list, err := mail.ParseAddressList(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient list '%s': %w", s, err)
+	}
+
+	result := make([]Recipient, 0, len(list))
+	for _, addr := range list {
+		result = append(result, Recipient{Name: addr.Name, Email: addr.Address})
+	}
+	return result, nil
+}
+
+// Normalized returns a copy of the recipient with its domain converted to its ASCII form
+// with the IDNA lookup profile: lower-cased, NFC normalized, and with its internationalized labels
+// converted to punycode, e.g. "joe@Bücher.example" becomes "joe@xn--bcher-kva.example".
+// The local part is left as is, and so is a domain that IDNA rejects.
+func (r Recipient) Normalized() Recipient {
+	i := strings.LastIndex(r.Email, "@")
+	if i < 0 {
+		return r
+	}
+
+	domain, err := idna.Lookup.ToASCII(r.Email[i+1:])
+	if err != nil {
+		domain = strings.ToLower(r.Email[i+1:])
+	}
+	r.Email = r.Email[:i+1] + domain
+	return r
+}
+
 func (r Recipient) String() string {
 	if r.Name != "" {
-		return fmt.Sprintf("%s <%s>", r.Name, r.Email)
+		return fmt.Sprintf("%s <%s>", quoteName(r.Name), r.Email)
 	}
 	return r.Email
 }
@@ -25,11 +69,19 @@ func (r Recipient) MarshalText() ([]byte, error) {
 // UnmarshalText satisfies TextUnmarshaler
 func (r *Recipient) UnmarshalText(text []byte) error {
 	s := string(text)
+	if s == "" {
+		return fmt.Errorf("malformed recipient string '%s'", s)
+	}
 	if s[len(s)-1:] != ">" {
 		*r = Recipient{Email: s}
 		return nil
 	}
 
+	if parsed, err := ParseRecipient(s); err == nil {
+		*r = parsed
+		return nil
+	}
+
 	i := strings.Index(s, "<")
 	// at least 1 char followed by a space
 	if i < 2 {
@@ -42,3 +94,175 @@ func (r *Recipient) UnmarshalText(text []byte) error {
 
 	return nil
 }
+
+// AddRecipients appends receivers to the To: header of a message.
+// Recipients are normalized (see Recipient.Normalized), and those already present
+// in the To:, Cc: or Bcc: headers are skipped.
+// It will return an error if the limit of recipients has been exceeded for this message
+func (m *PlainMessage) AddRecipients(recipients ...Recipient) error {
+	for _, r := range recipients {
+		r = r.Normalized()
+		if m.hasRecipient(r) {
+			continue
+		}
+		if err := m.AddRecipient(r.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddCCRecipients appends receivers to the carbon-copy header of a message.
+// Recipients are normalized, and those already present in the To:, Cc: or Bcc: headers are skipped.
+func (m *PlainMessage) AddCCRecipients(recipients ...Recipient) {
+	for _, r := range recipients {
+		r = r.Normalized()
+		if !m.hasRecipient(r) {
+			m.AddCC(r.String())
+		}
+	}
+}
+
+// AddBCCRecipients appends receivers to the blind-carbon-copy header of a message.
+// Recipients are normalized, and those already present in the To:, Cc: or Bcc: headers are skipped.
+func (m *PlainMessage) AddBCCRecipients(recipients ...Recipient) {
+	for _, r := range recipients {
+		r = r.Normalized()
+		if !m.hasRecipient(r) {
+			m.AddBCC(r.String())
+		}
+	}
+}
+
+// FromRecipient returns the parsed From: header of a message.
+func (m *PlainMessage) FromRecipient() (Recipient, error) {
+	return ParseRecipient(m.From())
+}
+
+// ToRecipients returns the parsed To: recipients of a message.
+func (m *CommonMessage) ToRecipients() ([]Recipient, error) {
+	return parseRecipients(m.To())
+}
+
+// CCRecipients returns the parsed Cc: recipients of a message.
+func (m *PlainMessage) CCRecipients() ([]Recipient, error) {
+	return parseRecipients(m.CC())
+}
+
+// BCCRecipients returns the parsed Bcc: recipients of a message.
+func (m *PlainMessage) BCCRecipients() ([]Recipient, error) {
+	return parseRecipients(m.BCC())
+}
+
+// NormalizeRecipients parses and normalizes all the To:, Cc: and Bcc: recipients
+// added as strings, and removes duplicates across these headers.
+// A recipient present in several headers is kept in the first of To:, Cc:, Bcc:.
+// Recipient variables follow the normalized To: recipients.
+func (m *PlainMessage) NormalizeRecipients() error {
+	var (
+		seen = make(map[string]bool)
+		to   []string
+		vars map[string]map[string]any
+	)
+
+	normalize := func(list []string, onAdd func(raw, normalized string)) ([]string, error) {
+		var result []string
+		for _, raw := range list {
+			rs, err := ParseRecipientList(raw)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range rs {
+				r = r.Normalized()
+				key := strings.ToLower(r.Email)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				result = append(result, r.String())
+				if onAdd != nil {
+					onAdd(raw, r.String())
+				}
+			}
+		}
+		return result, nil
+	}
+
+	to, err := normalize(m.to, func(raw, normalized string) {
+		v, ok := m.recipientVariables[raw]
+		if !ok {
+			return
+		}
+		if vars == nil {
+			vars = make(map[string]map[string]any)
+		}
+		vars[normalized] = v
+	})
+	if err != nil {
+		return err
+	}
+	cc, err := normalize(m.cc, nil)
+	if err != nil {
+		return err
+	}
+	bcc, err := normalize(m.bcc, nil)
+	if err != nil {
+		return err
+	}
+
+	m.to, m.cc, m.bcc = to, cc, bcc
+	if m.recipientVariables != nil {
+		m.recipientVariables = vars
+	}
+	return nil
+}
+
+// hasRecipient reports whether the normalized recipient is already present in To:, Cc: or Bcc:.
+func (m *PlainMessage) hasRecipient(r Recipient) bool {
+	email := strings.ToLower(r.Email)
+	for _, list := range [][]string{m.to, m.cc, m.bcc} {
+		for _, s := range list {
+			existing, err := ParseRecipient(s)
+			if err != nil {
+				continue
+			}
+			if strings.ToLower(existing.Normalized().Email) == email {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseRecipients(list []string) ([]Recipient, error) {
+	var result []Recipient
+	for _, s := range list {
+		rs, err := ParseRecipientList(s)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rs...)
+	}
+	return result, nil
+}
+
+// ValidateRecipients returns an error if any of the To:, Cc: or Bcc: recipients added as strings
+// is not a valid RFC 5322 address list. Send does not check them, so that strings accepted by the API
+// but not by net/mail, e.g. "Doe, John <john@example.com>", keep working: call it before Send to
+// check them strictly.
+func (m *PlainMessage) ValidateRecipients() error {
+	for _, list := range [][]string{m.To(), m.CC(), m.BCC()} {
+		if _, err := parseRecipients(list); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+		}
+	}
+	return nil
+}
+
+// quoteName quotes a display name if it contains RFC 5322 special characters.
+func quoteName(name string) string {
+	if !strings.ContainsAny(name, `()<>[]:;@\,."`) {
+		return name
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+}
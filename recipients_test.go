@@ -0,0 +1,111 @@
+package mailgun_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecipient(t *testing.T) {
+	r, err := mailgun.ParseRecipient(`"Doe, Joe" <joe@example.com>`)
+	require.NoError(t, err)
+	assert.Equal(t, mailgun.Recipient{Name: "Doe, Joe", Email: "joe@example.com"}, r)
+	assert.Equal(t, `"Doe, Joe" <joe@example.com>`, r.String())
+
+	rs, err := mailgun.ParseRecipientList("joe@example.com, Jane <jane@example.com>")
+	require.NoError(t, err)
+	assert.Equal(t, []mailgun.Recipient{
+		{Email: "joe@example.com"},
+		{Name: "Jane", Email: "jane@example.com"},
+	}, rs)
+
+	_, err = mailgun.ParseRecipient("joe@")
+	require.Error(t, err)
+}
+
+func TestRecipientNormalized(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "Joe@Example.COM", want: "Joe@example.com"},
+		{in: "joe@Bücher.example", want: "joe@xn--bcher-kva.example"},
+		// The decomposed form of "ü" is normalized first.
+		{in: "joe@Bu\u0308cher.example", want: "joe@xn--bcher-kva.example"},
+		{in: "joe@例え.テスト", want: "joe@xn--r8jz45g.xn--zckzah"},
+		{in: "joe", want: "joe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r := mailgun.Recipient{Email: tt.in}.Normalized()
+			assert.Equal(t, tt.want, r.Email)
+		})
+	}
+}
+
+func TestAddRecipients(t *testing.T) {
+	m := mailgun.NewMessage(testDomain, fromUser, exampleSubject, exampleText)
+	require.NoError(t, m.AddRecipients(
+		mailgun.Recipient{Name: "Joe", Email: "joe@Example.com"},
+		mailgun.Recipient{Email: "jane@bücher.example"},
+		mailgun.Recipient{Email: "joe@example.com"},
+	))
+	m.AddCCRecipients(mailgun.Recipient{Email: "jane@xn--bcher-kva.example"}, mailgun.Recipient{Email: "cc@example.com"})
+	m.AddBCCRecipients(mailgun.Recipient{Email: "CC@example.com"}, mailgun.Recipient{Email: "bcc@example.com"})
+
+	assert.Equal(t, []string{"Joe <joe@example.com>", "jane@xn--bcher-kva.example"}, m.To())
+	assert.Equal(t, []string{"cc@example.com"}, m.CC())
+	assert.Equal(t, []string{"bcc@example.com"}, m.BCC())
+
+	to, err := m.ToRecipients()
+	require.NoError(t, err)
+	assert.Equal(t, []mailgun.Recipient{
+		{Name: "Joe", Email: "joe@example.com"},
+		{Email: "jane@xn--bcher-kva.example"},
+	}, to)
+}
+
+func TestNormalizeRecipients(t *testing.T) {
+	m := mailgun.NewMessage(testDomain, fromUser, exampleSubject, exampleText)
+	require.NoError(t, m.AddRecipientAndVariables("Joe <joe@EXAMPLE.com>", map[string]any{"id": 1}))
+	require.NoError(t, m.AddRecipient("jane@example.com, joe@example.com"))
+	m.AddCC("jane@example.com")
+	m.AddBCC("bob@bücher.example")
+
+	require.NoError(t, m.NormalizeRecipients())
+	assert.Equal(t, []string{"Joe <joe@example.com>", "jane@example.com"}, m.To())
+	assert.Empty(t, m.CC())
+	assert.Equal(t, []string{"bob@xn--bcher-kva.example"}, m.BCC())
+	assert.Equal(t, map[string]map[string]any{"Joe <joe@example.com>": {"id": 1}}, m.RecipientVariables())
+
+	m.AddCC("not an address")
+	require.Error(t, m.NormalizeRecipients())
+}
+
+func TestValidateRecipients(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseMultipartForm(1<<20))
+		assert.Equal(t, []string{"Doe, John <john@example.com>"}, req.Form["to"])
+		fmt.Fprint(w, `{"message":"Queued, Thank you", "id":"<20111114174239.25659.5820@samples.mailgun.org>"}`)
+	}))
+	defer srv.Close()
+
+	mg := mailgun.NewMailgun(exampleAPIKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	// Send accepts the strings that net/mail does not parse.
+	m := mailgun.NewMessage(testDomain, fromUser, exampleSubject, exampleText, "Doe, John <john@example.com>")
+	_, err := mg.Send(context.Background(), m)
+	require.NoError(t, err)
+	require.ErrorIs(t, m.ValidateRecipients(), mailgun.ErrInvalidMessage)
+
+	m = mailgun.NewMessage(testDomain, fromUser, exampleSubject, exampleText, "joe@example.com")
+	require.NoError(t, m.ValidateRecipients())
+	m.AddCC("not an address")
+	require.ErrorIs(t, m.ValidateRecipients(), mailgun.ErrInvalidMessage)
+}
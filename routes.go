@@ -12,6 +12,7 @@ import (
 
 // ForwardedMessage represents the payload the server will get on match
 // You can use ExtractForwardRoute() to extract PostForm into the struct, or you can use only the struct and parse the form manually
+// It does not include the attachments, use webhooks.InboundHandler or webhooks.ParseInboundMessage to receive them
 // Documentation: https://documentation.mailgun.com/docs/mailgun/user-manual/receive-forward-store/receive-http
 type ForwardedMessage struct {
 	BodyPlain      string            // body-plain
@@ -25,6 +26,9 @@ type ForwardedMessage struct {
 	Subject        string            // subject
 	Timestamp      time.Time         // timestamp
 	Token          string            // token
+	// HeaderList are the headers of the message-headers field, in order and including the repeated headers.
+	// MessageHeaders only keeps the last value of each header.
+	HeaderList [][]string
 }
 
 // NotifiedMessage represents the payload the server will get on stored and notified
@@ -48,6 +52,9 @@ type NotifiedMessage struct {
 	Signature         string                    // signature
 	MessageHeaders    map[string]string         // message-headers
 	ContentIDMap      map[string]string         // content-id-map
+	// HeaderList are the headers of the message-headers field, in order and including the repeated headers.
+	// MessageHeaders only keeps the last value of each header.
+	HeaderList [][]string
 }
 
 // ExtractForwardedMessage extracts the forward route payload values from a parsed PostForm
@@ -93,6 +100,7 @@ func ExtractForwardedMessage(formValues url.Values) ForwardedMessage {
 		}
 	}
 	forwardedMessage.MessageHeaders = messageHeaders
+	forwardedMessage.HeaderList = headersParsed
 
 	return forwardedMessage
 }
@@ -153,6 +161,7 @@ func ExtractNotifiedMessage(formValues url.Values) NotifiedMessage {
 		}
 	}
 	notifiedMessage.MessageHeaders = messageHeaders
+	notifiedMessage.HeaderList = headersParsed
 
 	contentIDMapStr := formValues.Get("content-id-map")
 	contentIDMapParsed := make(map[string]string)
@@ -0,0 +1,460 @@
+package mailgun
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// Route actions, see RouteAction.
+const (
+	RouteActionForward = "forward"
+	RouteActionStore   = "store"
+	RouteActionStop    = "stop"
+)
+
+// RouteMessage is the inbound message a route expression is evaluated against.
+type RouteMessage struct {
+	// Recipient is the SMTP recipient of the message.
+	Recipient string
+	// Headers are the headers of the message, as in the message-headers field.
+	Headers [][]string
+}
+
+// RouteExpression is a parsed route filter expression, e.g.
+//
+//	match_recipient(".*@example.com") and match_header("subject", ".*urgent.*")
+//
+// It is made of the filters match_recipient(pattern), match_header(header, pattern) and catch_all(),
+// combined with "and", "or" and parentheses. Patterns are regular expressions searched in the recipient
+// or the header values; anchor them with ^ and $ to match the whole value. Named groups, e.g. (?P<user>.*),
+// are captured for the \g<user> references of the actions.
+type RouteExpression struct {
+	expr string
+	root routeNode
+}
+
+// ParseRouteExpression parses a route filter expression, see RouteExpression.
+func ParseRouteExpression(expr string) (*RouteExpression, error) {
+	tokens, err := tokenizeRoute(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid route expression '%s': %w", expr, err)
+	}
+	p := routeParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected '%s'", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid route expression '%s': %w", expr, err)
+	}
+	return &RouteExpression{expr: expr, root: root}, nil
+}
+
+// String returns the expression as parsed.
+func (e *RouteExpression) String() string {
+	return e.expr
+}
+
+// Match reports whether the message matches the expression, along with the named groups captured by its patterns.
+func (e *RouteExpression) Match(m *RouteMessage) (captures map[string]string, ok bool) {
+	captures = map[string]string{}
+	if !e.root.match(m, captures) {
+		return nil, false
+	}
+	return captures, true
+}
+
+// RouteAction is a parsed route action.
+type RouteAction struct {
+	// Name is RouteActionForward, RouteActionStore or RouteActionStop.
+	Name string
+	// Destination is the URL or e-mail address of a forward action, with the captured groups substituted.
+	Destination string
+	// Notify is the URL notified by a store action, with the captured groups substituted.
+	Notify string
+	// Raw is the action as configured in the route.
+	Raw string
+}
+
+// ParseRouteAction parses a route action: forward(destination), store(), store(notify=url) or stop().
+// The \g<name> references are substituted with the captures, see RouteExpression.Match.
+func ParseRouteAction(action string, captures map[string]string) (RouteAction, error) {
+	a := RouteAction{Raw: action}
+	tokens, err := tokenizeRoute(action)
+	if err != nil {
+		return a, fmt.Errorf("invalid route action '%s': %w", action, err)
+	}
+	p := routeParser{tokens: tokens}
+	name, args, kwargs, err := p.parseCall()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected '%s'", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return a, fmt.Errorf("invalid route action '%s': %w", action, err)
+	}
+
+	a.Name = name
+	switch {
+	case name == RouteActionForward && len(args) == 1 && len(kwargs) == 0:
+		a.Destination = substituteCaptures(args[0], captures)
+	case name == RouteActionStore && len(args) == 0:
+		for key, value := range kwargs {
+			if key != "notify" {
+				return a, fmt.Errorf("invalid route action '%s': unknown argument '%s'", action, key)
+			}
+			a.Notify = substituteCaptures(value, captures)
+		}
+	case name == RouteActionStop && len(args) == 0 && len(kwargs) == 0:
+	default:
+		return a, fmt.Errorf("invalid route action '%s': unknown action or wrong arguments", action)
+	}
+	return a, nil
+}
+
+// RouteMatch is a route matching a message, see SimulateRoutes.
+type RouteMatch struct {
+	Route mtypes.Route
+	// Captures are the named groups captured by the expression.
+	Captures map[string]string
+	// Actions are the actions of the route that fire.
+	Actions []RouteAction
+}
+
+// SimulateRoutes evaluates the routes against the message offline, as Mailgun does: by priority, lowest first,
+// then by creation time, until a matching route with a stop() action. It returns the matching routes in order,
+// and an error if an expression or an action is invalid.
+//
+//	var routes []mtypes.Route
+//	for route, err := range mg.AllRoutes(ctx, nil) {
+//		if err != nil {
+//			return err
+//		}
+//		routes = append(routes, route)
+//	}
+//	matches, err := mailgun.SimulateRoutes(routes, &mailgun.RouteMessage{
+//		Recipient: "support@example.com",
+//		Headers:   [][]string{{"Subject", "Urgent: server down"}},
+//	})
+func SimulateRoutes(routes []mtypes.Route, m *RouteMessage) ([]RouteMatch, error) {
+	sorted := slices.Clone(routes)
+	slices.SortStableFunc(sorted, func(a, b mtypes.Route) int {
+		if a.Priority != b.Priority {
+			return a.Priority - b.Priority
+		}
+		return time.Time(a.CreatedAt).Compare(time.Time(b.CreatedAt))
+	})
+
+	var matches []RouteMatch
+	for _, route := range sorted {
+		expr, err := ParseRouteExpression(route.Expression)
+		if err != nil {
+			return matches, fmt.Errorf("while evaluating route '%s': %w", route.Id, err)
+		}
+		captures, ok := expr.Match(m)
+		if !ok {
+			continue
+		}
+
+		match := RouteMatch{Route: route, Captures: captures}
+		stop := false
+		for _, raw := range route.Actions {
+			action, err := ParseRouteAction(raw, captures)
+			if err != nil {
+				return matches, fmt.Errorf("while evaluating route '%s': %w", route.Id, err)
+			}
+			match.Actions = append(match.Actions, action)
+			stop = stop || action.Name == RouteActionStop
+		}
+		matches = append(matches, match)
+		if stop {
+			break
+		}
+	}
+	return matches, nil
+}
+
+var captureReference = regexp.MustCompile(`\\g<(\w+)>`)
+
+func substituteCaptures(s string, captures map[string]string) string {
+	return captureReference.ReplaceAllStringFunc(s, func(ref string) string {
+		return captures[captureReference.FindStringSubmatch(ref)[1]]
+	})
+}
+
+// routeNode is a node of a route expression. It adds its captures to the map only if it matches.
+type routeNode interface {
+	match(m *RouteMessage, captures map[string]string) bool
+}
+
+type routeAnd []routeNode
+
+func (n routeAnd) match(m *RouteMessage, captures map[string]string) bool {
+	// The captures of the first operands are dropped if a later one does not match.
+	scratch := map[string]string{}
+	for _, node := range n {
+		if !node.match(m, scratch) {
+			return false
+		}
+	}
+	maps.Copy(captures, scratch)
+	return true
+}
+
+type routeOr []routeNode
+
+func (n routeOr) match(m *RouteMessage, captures map[string]string) bool {
+	for _, node := range n {
+		scratch := map[string]string{}
+		if node.match(m, scratch) {
+			maps.Copy(captures, scratch)
+			return true
+		}
+	}
+	return false
+}
+
+type routeCatchAll struct{}
+
+func (routeCatchAll) match(*RouteMessage, map[string]string) bool {
+	return true
+}
+
+type routeMatchRecipient struct {
+	pattern *regexp.Regexp
+}
+
+func (n routeMatchRecipient) match(m *RouteMessage, captures map[string]string) bool {
+	return matchPattern(n.pattern, m.Recipient, captures)
+}
+
+type routeMatchHeader struct {
+	header  string
+	pattern *regexp.Regexp
+}
+
+func (n routeMatchHeader) match(m *RouteMessage, captures map[string]string) bool {
+	for _, h := range m.Headers {
+		if len(h) >= 2 && strings.EqualFold(h[0], n.header) && matchPattern(n.pattern, h[1], captures) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPattern(pattern *regexp.Regexp, value string, captures map[string]string) bool {
+	groups := pattern.FindStringSubmatch(value)
+	if groups == nil {
+		return false
+	}
+	for i, name := range pattern.SubexpNames() {
+		if name != "" {
+			captures[name] = groups[i]
+		}
+	}
+	return true
+}
+
+type routeToken struct {
+	text string
+	// quoted is true for string literals.
+	quoted bool
+}
+
+// tokenizeRoute splits a route expression or action into identifiers, string literals and punctuation.
+// In string literals, only the quote and the backslash can be escaped: other escapes are kept for the patterns.
+func tokenizeRoute(s string) ([]routeToken, error) {
+	var tokens []routeToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("(),=", c) >= 0:
+			tokens = append(tokens, routeToken{text: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s) && s[j] != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) && (s[j+1] == c || s[j+1] == '\\') {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, routeToken{text: b.String(), quoted: true})
+			i = j + 1
+		case c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			j := i
+			for j < len(s) && (s[j] == '_' || ('a' <= s[j] && s[j] <= 'z') || ('A' <= s[j] && s[j] <= 'Z') ||
+				('0' <= s[j] && s[j] <= '9')) {
+				j++
+			}
+			tokens = append(tokens, routeToken{text: s[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character '%c'", c)
+		}
+	}
+	return tokens, nil
+}
+
+type routeParser struct {
+	tokens []routeToken
+	pos    int
+}
+
+func (p *routeParser) peek() (routeToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return routeToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *routeParser) keyword(word string) bool {
+	t, ok := p.peek()
+	if ok && !t.quoted && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *routeParser) expect(text string) error {
+	t, ok := p.peek()
+	if !ok {
+		return fmt.Errorf("expected '%s' at the end", text)
+	}
+	if t.quoted || t.text != text {
+		return fmt.Errorf("expected '%s', got '%s'", text, t.text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *routeParser) parseOr() (routeNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	or := routeOr{node}
+	for p.keyword("or") {
+		if node, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		or = append(or, node)
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *routeParser) parseAnd() (routeNode, error) {
+	node, err := p.parseFilter()
+	if err != nil {
+		return nil, err
+	}
+	and := routeAnd{node}
+	for p.keyword("and") {
+		if node, err = p.parseFilter(); err != nil {
+			return nil, err
+		}
+		and = append(and, node)
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *routeParser) parseFilter() (routeNode, error) {
+	if t, ok := p.peek(); ok && !t.quoted && t.text == "(" {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return node, p.expect(")")
+	}
+
+	name, args, kwargs, err := p.parseCall()
+	if err != nil {
+		return nil, err
+	}
+	if len(kwargs) > 0 {
+		return nil, fmt.Errorf("%s() takes no named arguments", name)
+	}
+	switch {
+	case name == "catch_all" && len(args) == 0:
+		return routeCatchAll{}, nil
+	case name == "match_recipient" && len(args) == 1:
+		pattern, err := regexp.Compile(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of match_recipient(): %w", err)
+		}
+		return routeMatchRecipient{pattern: pattern}, nil
+	case name == "match_header" && len(args) == 2:
+		pattern, err := regexp.Compile(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of match_header(): %w", err)
+		}
+		return routeMatchHeader{header: args[0], pattern: pattern}, nil
+	default:
+		return nil, fmt.Errorf("unknown filter %s() with %d arguments", name, len(args))
+	}
+}
+
+// parseCall parses name(arg, key=arg, ...) where the arguments are string literals.
+func (p *routeParser) parseCall() (name string, args []string, kwargs map[string]string, err error) {
+	t, ok := p.peek()
+	if !ok || t.quoted || t.text == "(" || t.text == ")" || t.text == "," || t.text == "=" {
+		return "", nil, nil, errors.New("expected a function call")
+	}
+	name = strings.ToLower(t.text)
+	p.pos++
+	if err := p.expect("("); err != nil {
+		return "", nil, nil, err
+	}
+
+	kwargs = map[string]string{}
+	for i := 0; ; i++ {
+		if t, ok := p.peek(); ok && !t.quoted && t.text == ")" {
+			p.pos++
+			return name, args, kwargs, nil
+		}
+		if i > 0 {
+			if err := p.expect(","); err != nil {
+				return "", nil, nil, err
+			}
+		}
+
+		t, ok := p.peek()
+		if !ok {
+			return "", nil, nil, errors.New("expected ')' at the end")
+		}
+		p.pos++
+		if t.quoted {
+			args = append(args, t.text)
+			continue
+		}
+		if err := p.expect("="); err != nil {
+			return "", nil, nil, err
+		}
+		value, ok := p.peek()
+		if !ok || !value.quoted {
+			return "", nil, nil, fmt.Errorf("expected a string for argument '%s'", t.text)
+		}
+		p.pos++
+		kwargs[t.text] = value.text
+	}
+}
@@ -0,0 +1,143 @@
+package mailgun_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRouteExpression(t *testing.T) {
+	msg := &mailgun.RouteMessage{
+		Recipient: "bob@example.com",
+		Headers:   [][]string{{"Subject", "Re: urgent issue"}, {"X-Priority", "1"}},
+	}
+
+	for expr, want := range map[string]bool{
+		`catch_all()`:                                                                         true,
+		`match_recipient(".*@example.com")`:                                                   true,
+		`match_recipient('^alice@example\.com$')`:                                             false,
+		`match_header("subject", ".*urgent.*")`:                                               true,
+		`match_header("X-Missing", ".*")`:                                                     false,
+		`MATCH_HEADER("SUBJECT", "urgent")`:                                                   true,
+		`match_recipient("bob@") and match_header("x-priority", "^1$")`:                       true,
+		`match_recipient("alice@") and catch_all()`:                                           false,
+		`match_recipient("alice@") or match_header("subject", "urgent")`:                      true,
+		`match_recipient("alice@") or (catch_all() and match_header("subject", "^nothing$"))`: false,
+		`match_header("subject", "say \"hi\"")`:                                               false,
+	} {
+		e, err := mailgun.ParseRouteExpression(expr)
+		require.NoError(t, err, expr)
+		_, ok := e.Match(msg)
+		assert.Equal(t, want, ok, expr)
+	}
+
+	for _, expr := range []string{
+		``,
+		`catch_all`,
+		`catch_all() and`,
+		`match_recipient(".*"`,
+		`match_recipient("(?=lookahead)")`,
+		`match_header("subject")`,
+		`unknown_filter()`,
+		`catch_all() catch_all()`,
+		`match_recipient("unterminated)`,
+		`(catch_all()`,
+	} {
+		_, err := mailgun.ParseRouteExpression(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestRouteExpressionCaptures(t *testing.T) {
+	expr, err := mailgun.ParseRouteExpression(
+		`(match_recipient("(?P<u>.*)@a") and match_header("subject", "(?P<s>urgent)")) or catch_all()`)
+	require.NoError(t, err)
+
+	// The captures of a failed branch are dropped.
+	captures, ok := expr.Match(&mailgun.RouteMessage{Recipient: "bob@a", Headers: [][]string{{"Subject", "hello"}}})
+	assert.True(t, ok)
+	assert.Empty(t, captures)
+
+	captures, ok = expr.Match(&mailgun.RouteMessage{Recipient: "bob@a", Headers: [][]string{{"Subject", "urgent"}}})
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"u": "bob", "s": "urgent"}, captures)
+}
+
+func TestParseRouteAction(t *testing.T) {
+	captures := map[string]string{"user": "bob"}
+
+	a, err := mailgun.ParseRouteAction(`forward("https://example.com/inbound?user=\g<user>")`, captures)
+	require.NoError(t, err)
+	assert.Equal(t, mailgun.RouteActionForward, a.Name)
+	assert.Equal(t, "https://example.com/inbound?user=bob", a.Destination)
+
+	a, err = mailgun.ParseRouteAction(`store(notify="https://example.com/stored")`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, mailgun.RouteAction{
+		Name: mailgun.RouteActionStore, Notify: "https://example.com/stored", Raw: `store(notify="https://example.com/stored")`,
+	}, a)
+
+	a, err = mailgun.ParseRouteAction(`stop()`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, mailgun.RouteActionStop, a.Name)
+
+	for _, action := range []string{`forward()`, `store(url="x")`, `stop("now")`, `drop()`, `forward("a") stop()`} {
+		_, err := mailgun.ParseRouteAction(action, nil)
+		assert.Error(t, err, action)
+	}
+}
+
+func TestSimulateRoutes(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	routes := []mtypes.Route{
+		{
+			Id: "catch-all", Priority: 10, Expression: `catch_all()`,
+			Actions: []string{`store(notify="https://example.com/stored")`},
+		},
+		{
+			Id: "support", Priority: 1, Expression: `match_recipient("^(?P<user>[^@]+)@support.example.com$")`,
+			Actions:   []string{`forward("https://example.com/support/\g<user>")`},
+			CreatedAt: mtypes.RFC2822Time(created.Add(time.Hour)),
+		},
+		{
+			Id: "urgent", Priority: 1, Expression: `match_header("subject", "(?i)urgent")`,
+			Actions:   []string{`forward("oncall@example.com")`, `stop()`},
+			CreatedAt: mtypes.RFC2822Time(created.Add(2 * time.Hour)),
+		},
+		{
+			Id: "archive", Priority: 1, Expression: `match_recipient(".*@support.example.com")`,
+			Actions:   []string{`forward("archive@example.com")`},
+			CreatedAt: mtypes.RFC2822Time(created),
+		},
+	}
+
+	matches, err := mailgun.SimulateRoutes(routes, &mailgun.RouteMessage{
+		Recipient: "billing@support.example.com",
+		Headers:   [][]string{{"Subject", "Invoice"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	assert.Equal(t, "archive", matches[0].Route.Id)
+	assert.Equal(t, "support", matches[1].Route.Id)
+	assert.Equal(t, map[string]string{"user": "billing"}, matches[1].Captures)
+	assert.Equal(t, "https://example.com/support/billing", matches[1].Actions[0].Destination)
+	assert.Equal(t, "catch-all", matches[2].Route.Id)
+	assert.Equal(t, "https://example.com/stored", matches[2].Actions[0].Notify)
+
+	// stop() ends the evaluation.
+	matches, err = mailgun.SimulateRoutes(routes, &mailgun.RouteMessage{
+		Recipient: "billing@support.example.com",
+		Headers:   [][]string{{"Subject", "URGENT: server down"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	assert.Equal(t, "urgent", matches[2].Route.Id)
+	assert.Equal(t, mailgun.RouteActionStop, matches[2].Actions[1].Name)
+
+	_, err = mailgun.SimulateRoutes([]mtypes.Route{{Id: "broken", Expression: "match_recipient("}}, &mailgun.RouteMessage{})
+	assert.ErrorContains(t, err, "broken")
+}
@@ -0,0 +1,222 @@
+package mailgun
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// SandboxOriginalRecipientsHeader is the default header that receives the original
+// To: and Cc: recipients of a message rewritten by the sandbox mode.
+const SandboxOriginalRecipientsHeader = "X-Sandbox-Original-Recipients"
+
+// ErrSandboxNoRecipients is returned by `Send()` when the sandbox mode dropped every recipient of a message.
+var ErrSandboxNoRecipients = errors.New("sandbox: no allowed recipients left")
+
+// SandboxOptions configures the recipient redirection mode used by non-production environments.
+// Once installed with SetSandbox, every To:, Cc: and Bcc: recipient sent through the client
+// is either kept (if allow-listed) or replaced by CatchAll.
+type SandboxOptions struct {
+	// AllowedRecipients are delivered as is. An entry starting with "@" allows
+	// the whole domain, e.g. "@example.com". Matching is case-insensitive.
+	AllowedRecipients []string
+	// CatchAll receives messages for every recipient that is not allowed.
+	// If empty, such recipients are dropped.
+	CatchAll string
+	// Header receives the comma-separated list of the original To: and Cc: recipients.
+	// The Bcc: recipients are left out, as the header is visible to every recipient.
+	// Defaults to SandboxOriginalRecipientsHeader.
+	Header string
+	// EnableTestMode forces o:testmode, so Mailgun accepts but never delivers the message.
+	EnableTestMode bool
+}
+
+// SetSandbox enables the recipient redirection mode for this client. Pass nil to disable it.
+//
+//	mg.SetSandbox(&mailgun.SandboxOptions{
+//		AllowedRecipients: []string{"@staging.example.com"},
+//		CatchAll:          "qa@staging.example.com",
+//	})
+func (mg *Client) SetSandbox(opts *SandboxOptions) {
+	mg.sandbox = opts
+}
+
+// Sandbox returns the sandbox options configured for this client, or nil if the sandbox mode is disabled.
+func (mg *Client) Sandbox() *SandboxOptions {
+	return mg.sandbox
+}
+
+// isAllowed reports whether the address is allow-listed.
+func (o *SandboxOptions) isAllowed(address string) bool {
+	address = strings.ToLower(address)
+	for _, allowed := range o.AllowedRecipients {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if strings.HasPrefix(allowed, "@") {
+			if strings.HasSuffix(address, allowed) {
+				return true
+			}
+			continue
+		}
+		if address == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// rewrite returns the recipient to send to instead of r, or an empty string if r must be dropped.
+func (o *SandboxOptions) rewrite(r string) string {
+	if o.isAllowed(bareAddress(r)) {
+		return r
+	}
+	return o.CatchAll
+}
+
+// bareAddress returns the address of the recipient without its display name,
+// e.g. to look up its recipient variables, or the trimmed recipient if it cannot be parsed.
+func bareAddress(r string) string {
+	if addr, err := mail.ParseAddress(r); err == nil {
+		return addr.Address
+	}
+	return strings.TrimSpace(r)
+}
+
+// apply rewrites the recipient fields of the payload in place.
+func (o *SandboxOptions) apply(p *FormDataPayload) error {
+	var (
+		original   []string
+		count      int
+		values     []keyValuePair
+		recipients = make(map[string][]string)
+		rewritten  [][2]string
+		seen       = make(map[string]bool)
+		rcptVars   string
+	)
+
+	for _, kv := range p.Values {
+		switch kv.key {
+		case "to", "cc", "bcc":
+			recipients[kv.key] = append(recipients[kv.key], splitRecipients(kv.value)...)
+		case "recipient-variables":
+			rcptVars = kv.value
+		case "o:testmode":
+			if !o.EnableTestMode {
+				values = append(values, kv)
+			}
+		default:
+			values = append(values, kv)
+		}
+	}
+
+	// To: is processed first, so that a recipient present in several fields stays in To:.
+	for _, key := range []string{"to", "cc", "bcc"} {
+		for _, r := range recipients[key] {
+			count++
+			if key != "bcc" {
+				original = append(original, r)
+			}
+			to := o.rewrite(r)
+			if to == "" {
+				continue
+			}
+			rewritten = append(rewritten, [2]string{r, to})
+			if seen[to] {
+				continue
+			}
+			seen[to] = true
+			values = append(values, keyValuePair{key: key, value: to})
+		}
+	}
+
+	if count != 0 && len(seen) == 0 {
+		return ErrSandboxNoRecipients
+	}
+
+	// The variables follow the recipients kept in any field, even when no To: recipient is left.
+	if rcptVars != "" {
+		var vars map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(rcptVars), &vars); err != nil {
+			return fmt.Errorf("sandbox: while decoding recipient-variables: %w", err)
+		}
+		newVars := make(map[string]json.RawMessage, len(vars))
+		for _, pair := range rewritten {
+			// The variables are keyed by the recipient as written, or by its bare address,
+			// which is the key Mailgun matches.
+			v, ok := vars[pair[0]]
+			if !ok {
+				v, ok = vars[bareAddress(pair[0])]
+			}
+			if !ok {
+				continue
+			}
+			// The first recipient redirected to an address keeps its variables.
+			to := bareAddress(pair[1])
+			if _, exists := newVars[to]; !exists {
+				newVars[to] = v
+			}
+		}
+		j, err := json.Marshal(newVars)
+		if err != nil {
+			return err
+		}
+		values = append(values, keyValuePair{key: "recipient-variables", value: string(j)})
+	}
+
+	header := o.Header
+	if header == "" {
+		header = SandboxOriginalRecipientsHeader
+	}
+	if len(original) != 0 {
+		values = append(values, keyValuePair{key: "h:" + header, value: strings.Join(original, ", ")})
+	}
+	if o.EnableTestMode {
+		values = append(values, keyValuePair{key: "o:testmode", value: "yes"})
+	}
+
+	p.Values = values
+	return nil
+}
+
+// splitRecipients splits a comma-separated recipient list, keeping each recipient as written,
+// display name included.
+func splitRecipients(s string) []string {
+	list, err := mail.ParseAddressList(s)
+	if err != nil || len(list) < 2 {
+		return []string{s}
+	}
+
+	var (
+		result   []string
+		start    int
+		quoted   bool
+		escaped  bool
+		brackets int
+	)
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '<':
+			brackets++
+		case c == '>' && brackets > 0:
+			brackets--
+		case c == ',' && brackets == 0:
+			result = append(result, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	result = append(result, strings.TrimSpace(s[start:]))
+
+	// Commas in comments or group syntax are not handled: keep the list as is.
+	if len(result) != len(list) {
+		return []string{s}
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package mailgun_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendSandbox(t *testing.T) {
+	const (
+		exampleDomain = "testDomain"
+		catchAll      = "qa@staging.test"
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		require.NoError(t, req.ParseMultipartForm(1<<20))
+
+		assert.Equal(t, []string{catchAll, "dev@allowed.test"}, req.Form["to"])
+		assert.Equal(t, []string{"Boss <boss@allowed.test>"}, req.Form["cc"])
+		assert.Empty(t, req.Form["bcc"])
+		// The Bcc: recipients stay hidden.
+		assert.Equal(t, "customer@example.com, other@example.com, dev@allowed.test, Boss <boss@allowed.test>",
+			req.FormValue("h:"+mailgun.SandboxOriginalRecipientsHeader))
+		assert.JSONEq(t, `{"qa@staging.test": {"name": "Customer"}, "dev@allowed.test": {"name": "Dev"}}`,
+			req.FormValue("recipient-variables"))
+		assert.Equal(t, "yes", req.FormValue("o:testmode"))
+
+		fmt.Fprint(w, `{"message":"Queued, Thank you", "id":"<20111114174239.25659.5820@samples.mailgun.org>"}`)
+	}))
+	defer srv.Close()
+
+	mg := mailgun.NewMailgun(exampleAPIKey)
+	err := mg.SetAPIBase(srv.URL)
+	require.NoError(t, err)
+	mg.SetSandbox(&mailgun.SandboxOptions{
+		AllowedRecipients: []string{"@allowed.test"},
+		CatchAll:          catchAll,
+		EnableTestMode:    true,
+	})
+
+	m := mailgun.NewMessage(exampleDomain, fromUser, exampleSubject, exampleText)
+	require.NoError(t, m.AddRecipientAndVariables("customer@example.com", map[string]any{"name": "Customer"}))
+	require.NoError(t, m.AddRecipientAndVariables("other@example.com", map[string]any{"name": "Other"}))
+	require.NoError(t, m.AddRecipientAndVariables("dev@allowed.test", map[string]any{"name": "Dev"}))
+	m.AddCC("Boss <boss@allowed.test>")
+	m.AddBCC("audit@example.com")
+
+	_, err = mg.Send(context.Background(), m)
+	require.NoError(t, err)
+}
+
+func TestSendSandboxCCOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseMultipartForm(1<<20))
+
+		assert.Empty(t, req.Form["to"])
+		assert.Equal(t, []string{"boss@allowed.test"}, req.Form["cc"])
+		// The variables of the dropped To: recipient are removed, but the field is kept.
+		assert.JSONEq(t, `{}`, req.FormValue("recipient-variables"))
+
+		fmt.Fprint(w, `{"message":"Queued, Thank you", "id":"<20111114174239.25659.5820@samples.mailgun.org>"}`)
+	}))
+	defer srv.Close()
+
+	mg := mailgun.NewMailgun(exampleAPIKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+	mg.SetSandbox(&mailgun.SandboxOptions{
+		AllowedRecipients: []string{"@allowed.test"},
+	})
+
+	m := mailgun.NewMessage("testDomain", fromUser, exampleSubject, exampleText)
+	require.NoError(t, m.AddRecipientAndVariables("customer@example.com", map[string]any{"name": "Customer"}))
+	m.AddCC("boss@allowed.test")
+
+	_, err := mg.Send(context.Background(), m)
+	require.NoError(t, err)
+}
+
+func TestSendSandboxRecipientList(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseMultipartForm(1<<20))
+
+		// The recipients are kept as written.
+		assert.Equal(t, []string{"John Doe <john@allowed.test>", "QA <qa@staging.test>", "Dev <dev@allowed.test>"},
+			req.Form["to"])
+		assert.JSONEq(t, `{"qa@staging.test": {"name": "Jane"}, "dev@allowed.test": {"name": "Dev"}}`,
+			req.FormValue("recipient-variables"))
+
+		fmt.Fprint(w, `{"message":"Queued, Thank you", "id":"<20111114174239.25659.5820@samples.mailgun.org>"}`)
+	}))
+	defer srv.Close()
+
+	mg := mailgun.NewMailgun(exampleAPIKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+	mg.SetSandbox(&mailgun.SandboxOptions{
+		AllowedRecipients: []string{"@allowed.test"},
+		CatchAll:          "QA <qa@staging.test>",
+	})
+
+	m := mailgun.NewMessage("testDomain", fromUser, exampleSubject, exampleText)
+	require.NoError(t, m.AddRecipient("John Doe <john@allowed.test>, Jane <jane@example.com>"))
+	// Variables keyed by the bare address of a recipient written with a display name.
+	require.NoError(t, m.AddRecipientAndVariables("jane@example.com", map[string]any{"name": "Jane"}))
+	require.NoError(t, m.AddRecipientAndVariables("Dev <dev@allowed.test>", map[string]any{"name": "Dev"}))
+
+	_, err := mg.Send(context.Background(), m)
+	require.NoError(t, err)
+}
+
+func TestSendSandboxNoRecipients(t *testing.T) {
+	mg := mailgun.NewMailgun(exampleAPIKey)
+	mg.SetSandbox(&mailgun.SandboxOptions{
+		AllowedRecipients: []string{"dev@allowed.test"},
+	})
+
+	m := mailgun.NewMessage("testDomain", fromUser, exampleSubject, exampleText, "customer@example.com")
+	_, err := mg.Send(context.Background(), m)
+	require.ErrorIs(t, err, mailgun.ErrSandboxNoRecipients)
+}
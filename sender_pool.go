@@ -0,0 +1,270 @@
+package mailgun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// ErrNoSenderDomain is returned by `SenderPool.Send()` when no sending domain is available for a message.
+var ErrNoSenderDomain = errors.New("sender pool: no sending domain available")
+
+// SenderDomain is a sending domain of a SenderPool.
+type SenderDomain struct {
+	// Name of the sending domain, e.g. "mg1.example.com".
+	Name string
+	// Weight of the domain in the random selection. Domains with a zero weight
+	// are only used for messages matched by Tags or RecipientDomains.
+	Weight int
+	// Tags routes the messages having any of these tags to this domain.
+	Tags []string
+	// RecipientDomains routes the messages whose first To: recipient belongs
+	// to any of these domains, e.g. "gmail.com", to this domain.
+	RecipientDomains []string
+}
+
+// SenderPoolOptions modifies the behavior of a SenderPool.
+type SenderPoolOptions struct {
+	// Cooldown is how long a domain is skipped after a failover. Defaults to 5 minutes.
+	Cooldown time.Duration
+	// ShouldFailover reports whether an error returned by `Send()` means that the domain
+	// cannot send and that the message should be sent through another domain.
+	// Defaults to IsSenderDomainError.
+	ShouldFailover func(err error) bool
+	// FallbackToPool sends the messages matched by Tags or RecipientDomains through the weighted
+	// domains when every matched domain is disabled. By default, `Send()` returns ErrNoSenderDomain,
+	// so a message is never sent through a domain it was not routed to.
+	FallbackToPool bool
+}
+
+// SenderPoolResponse is the response of `SenderPool.Send()`.
+type SenderPoolResponse struct {
+	mtypes.SendMessageResponse
+	// Domain is the sending domain which accepted the message.
+	Domain string
+	// FailedDomains are the domains tried before Domain, in order.
+	FailedDomains []string
+}
+
+// SenderPool sends messages through several sending domains, choosing a domain per message
+// by tags, recipient domain or weights, and failing over to another domain when a domain cannot send.
+//
+//	pool := mailgun.NewSenderPool(mg, []mailgun.SenderDomain{
+//		{Name: "mg1.example.com", Weight: 3},
+//		{Name: "mg2.example.com", Weight: 1},
+//		{Name: "news.example.com", Tags: []string{"newsletter"}},
+//	}, nil)
+//
+//	m := mailgun.NewMessage("", "me@example.com", "Hello", "Hello world!", "you@example.com")
+//	resp, err := pool.Send(ctx, m)
+//	fmt.Println(resp.Domain)
+type SenderPool struct {
+	mg      Mailgun
+	domains []SenderDomain
+	opts    SenderPoolOptions
+
+	mutex    sync.Mutex
+	disabled map[string]time.Time
+}
+
+// NewSenderPool creates a new sender pool. The domain of the messages sent through the pool is overwritten.
+func NewSenderPool(mg Mailgun, domains []SenderDomain, opts *SenderPoolOptions) *SenderPool {
+	p := &SenderPool{
+		mg:       mg,
+		domains:  domains,
+		disabled: make(map[string]time.Time),
+	}
+	if opts != nil {
+		p.opts = *opts
+	}
+	if p.opts.Cooldown == 0 {
+		p.opts.Cooldown = 5 * time.Minute
+	}
+	if p.opts.ShouldFailover == nil {
+		p.opts.ShouldFailover = IsSenderDomainError
+	}
+	return p
+}
+
+// Send chooses a sending domain for the message, sets it, and sends the message.
+// If the domain cannot send (see SenderPoolOptions.ShouldFailover), the domain is disabled for
+// the cooldown period and the message is sent through the next chosen domain.
+//
+// Messages whose content is read from an io.Reader (MIME messages, reader attachments and inlines)
+// are consumed by the first attempt, so they are never failed over.
+func (p *SenderPool) Send(ctx context.Context, m Message) (SenderPoolResponse, error) {
+	var resp SenderPoolResponse
+
+	dm, ok := m.(interface{ AddDomain(string) })
+	if !ok {
+		return resp, fmt.Errorf("sender pool: message of type %T does not support setting the domain", m)
+	}
+
+	for {
+		domain := p.choose(m, resp.FailedDomains)
+		if domain == "" {
+			if len(resp.FailedDomains) == 0 {
+				return resp, ErrNoSenderDomain
+			}
+			return resp, fmt.Errorf("%w: tried %s", ErrNoSenderDomain, strings.Join(resp.FailedDomains, ", "))
+		}
+
+		dm.AddDomain(domain)
+		sent, err := p.mg.Send(ctx, m)
+		if err == nil {
+			resp.SendMessageResponse = sent
+			resp.Domain = domain
+			return resp, nil
+		}
+
+		if !p.opts.ShouldFailover(err) {
+			return resp, err
+		}
+		p.disable(domain)
+		resp.FailedDomains = append(resp.FailedDomains, domain)
+
+		if !isReplayable(m) {
+			return resp, err
+		}
+	}
+}
+
+// Disabled returns the domains currently skipped after a failover.
+func (p *SenderPool) Disabled() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var result []string
+	now := time.Now()
+	for _, d := range p.domains {
+		if until, ok := p.disabled[d.Name]; ok && now.Before(until) {
+			result = append(result, d.Name)
+		}
+	}
+	return result
+}
+
+// Enable makes a disabled domain available again before the end of its cooldown period.
+func (p *SenderPool) Enable(domain string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.disabled, domain)
+}
+
+func (p *SenderPool) disable(domain string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.disabled[domain] = time.Now().Add(p.opts.Cooldown)
+}
+
+// choose returns the domain to send the message through, or an empty string if none is available.
+func (p *SenderPool) choose(m Message, exclude []string) string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	available := func(domains []SenderDomain) []SenderDomain {
+		return slices.DeleteFunc(slices.Clone(domains), func(d SenderDomain) bool {
+			until, ok := p.disabled[d.Name]
+			return ok && now.Before(until) || slices.Contains(exclude, d.Name)
+		})
+	}
+
+	for _, matched := range [][]SenderDomain{matchTags(p.domains, m.Tags()), matchRecipientDomain(p.domains, m.To())} {
+		if len(matched) == 0 {
+			continue
+		}
+		if domain := pickWeighted(available(matched), 1); domain != "" || !p.opts.FallbackToPool {
+			return domain
+		}
+	}
+	return pickWeighted(available(p.domains), 0)
+}
+
+func matchTags(domains []SenderDomain, tags []string) []SenderDomain {
+	var result []SenderDomain
+	for _, d := range domains {
+		for _, tag := range tags {
+			if slices.Contains(d.Tags, tag) {
+				result = append(result, d)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func matchRecipientDomain(domains []SenderDomain, to []string) []SenderDomain {
+	if len(to) == 0 {
+		return nil
+	}
+
+	email := to[0]
+	if r, err := ParseRecipient(email); err == nil {
+		email = r.Email
+	}
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return nil
+	}
+	rcptDomain := strings.ToLower(email[i+1:])
+
+	var result []SenderDomain
+	for _, d := range domains {
+		for _, rd := range d.RecipientDomains {
+			if strings.EqualFold(rd, rcptDomain) {
+				result = append(result, d)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// pickWeighted picks a random domain proportionally to its weight.
+// Weights lower than minWeight are raised to minWeight.
+func pickWeighted(domains []SenderDomain, minWeight int) string {
+	total := 0
+	for _, d := range domains {
+		total += max(d.Weight, minWeight)
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	n := rand.IntN(total)
+	for _, d := range domains {
+		n -= max(d.Weight, minWeight)
+		if n < 0 {
+			return d.Name
+		}
+	}
+	return ""
+}
+
+// isReplayable reports whether the message can be sent again after a failed attempt.
+func isReplayable(m Message) bool {
+	if _, ok := m.(*MimeMessage); ok {
+		return false
+	}
+	return len(m.ReaderAttachments()) == 0 && len(m.ReaderInlines()) == 0
+}
+
+// IsSenderDomainError reports whether the error returned by `Send()` means that the sending domain
+// cannot send: 403 Forbidden, e.g. because the domain is unverified, disabled or suspended,
+// or 404 Not Found for an unknown domain. Other errors, e.g. an invalid message, are not failed over.
+func IsSenderDomainError(err error) bool {
+	var apiErr *UnexpectedResponseError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Actual == http.StatusForbidden || apiErr.Actual == http.StatusNotFound
+}
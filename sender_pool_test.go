@@ -0,0 +1,134 @@
+package mailgun_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSenderPoolServer creates a client sending to a server failing with the status code of the failing domains.
+func newSenderPoolServer(t *testing.T, failing map[string]int) (*mailgun.Client, *[]string) {
+	var (
+		mutex sync.Mutex
+		used  []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		domain := strings.Split(strings.TrimPrefix(req.URL.Path, "/v3/"), "/")[0]
+		mutex.Lock()
+		used = append(used, domain)
+		mutex.Unlock()
+
+		if status, ok := failing[domain]; ok {
+			w.WriteHeader(status)
+			fmt.Fprint(w, `{"message":"Failed"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"message":"Queued. Thank you.", "id":"<id@%s>"}`, domain)
+	}))
+	t.Cleanup(srv.Close)
+
+	mg := mailgun.NewMailgun(exampleAPIKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+	return mg, &used
+}
+
+func TestSenderPoolRouting(t *testing.T) {
+	mg, _ := newSenderPoolServer(t, nil)
+	pool := mailgun.NewSenderPool(mg, []mailgun.SenderDomain{
+		{Name: "main.test", Weight: 1},
+		{Name: "news.test", Tags: []string{"newsletter"}},
+		{Name: "gmail.test", RecipientDomains: []string{"gmail.com"}},
+	}, nil)
+	ctx := context.Background()
+
+	m := mailgun.NewMessage("", fromUser, exampleSubject, exampleText, "joe@example.com")
+	resp, err := pool.Send(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, "main.test", resp.Domain)
+	assert.Equal(t, "<id@main.test>", resp.ID)
+	assert.Equal(t, "main.test", m.Domain())
+
+	m = mailgun.NewMessage("", fromUser, exampleSubject, exampleText, "joe@example.com")
+	require.NoError(t, m.AddTag("newsletter"))
+	resp, err = pool.Send(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, "news.test", resp.Domain)
+
+	m = mailgun.NewMessage("", fromUser, exampleSubject, exampleText, "Joe <joe@GMail.com>")
+	resp, err = pool.Send(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, "gmail.test", resp.Domain)
+}
+
+func TestSenderPoolFailover(t *testing.T) {
+	mg, used := newSenderPoolServer(t, map[string]int{
+		"bad.test": http.StatusForbidden,
+	})
+	pool := mailgun.NewSenderPool(mg, []mailgun.SenderDomain{
+		{Name: "bad.test", RecipientDomains: []string{"example.com"}},
+		{Name: "good.test", Weight: 1},
+	}, &mailgun.SenderPoolOptions{FallbackToPool: true})
+	ctx := context.Background()
+
+	m := mailgun.NewMessage("", fromUser, exampleSubject, exampleText, "joe@example.com")
+	resp, err := pool.Send(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, "good.test", resp.Domain)
+	assert.Equal(t, []string{"bad.test"}, resp.FailedDomains)
+	assert.Equal(t, []string{"bad.test"}, pool.Disabled())
+
+	// The disabled domain is skipped.
+	resp, err = pool.Send(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, "good.test", resp.Domain)
+	assert.Empty(t, resp.FailedDomains)
+	assert.Equal(t, []string{"bad.test", "good.test", "good.test"}, *used)
+
+	pool.Enable("bad.test")
+	assert.Empty(t, pool.Disabled())
+}
+
+func TestSenderPoolNoFallback(t *testing.T) {
+	mg, used := newSenderPoolServer(t, map[string]int{
+		"bad.test": http.StatusNotFound,
+	})
+	pool := mailgun.NewSenderPool(mg, []mailgun.SenderDomain{
+		{Name: "bad.test", Tags: []string{"newsletter"}},
+		{Name: "good.test", Weight: 1},
+	}, nil)
+
+	m := mailgun.NewMessage("", fromUser, exampleSubject, exampleText, "joe@example.com")
+	require.NoError(t, m.AddTag("newsletter"))
+	resp, err := pool.Send(context.Background(), m)
+	require.ErrorIs(t, err, mailgun.ErrNoSenderDomain)
+	assert.Equal(t, []string{"bad.test"}, resp.FailedDomains)
+	assert.Equal(t, []string{"bad.test"}, *used)
+}
+
+func TestSenderPoolNoFailover(t *testing.T) {
+	mg, _ := newSenderPoolServer(t, map[string]int{
+		"a.test": http.StatusBadRequest,
+		"b.test": http.StatusForbidden,
+	})
+	ctx := context.Background()
+
+	pool := mailgun.NewSenderPool(mg, []mailgun.SenderDomain{{Name: "a.test", Weight: 1}}, nil)
+	m := mailgun.NewMessage("", fromUser, exampleSubject, exampleText, "joe@example.com")
+	_, err := pool.Send(ctx, m)
+	assert.Equal(t, http.StatusBadRequest, mailgun.GetStatusFromErr(err))
+	assert.Empty(t, pool.Disabled())
+
+	pool = mailgun.NewSenderPool(mg, []mailgun.SenderDomain{{Name: "b.test", Weight: 1}}, nil)
+	resp, err := pool.Send(ctx, m)
+	require.ErrorIs(t, err, mailgun.ErrNoSenderDomain)
+	assert.Equal(t, []string{"b.test"}, resp.FailedDomains)
+}
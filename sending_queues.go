@@ -0,0 +1,32 @@
+package mailgun
+
+import (
+	"context"
+
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// GetDomainSendingQueues returns the status of the regular and scheduled sending queues of a domain.
+// The API does not list the messages of a queue: to find the scheduled messages, keep the IDs
+// returned by Send for the messages sent with SetDeliveryTime or SetSTOPeriod.
+func (mg *Client) GetDomainSendingQueues(ctx context.Context, domain string) (mtypes.DomainSendingQueues, error) {
+	r := newHTTPRequest(generateV3DomainsApiUrl(mg, "sending_queues", domain))
+	r.setClient(mg.HTTPClient())
+	r.setBasicAuth(basicAuthUser, mg.APIKey())
+
+	var resp mtypes.DomainSendingQueues
+	err := getResponseFromJSON(ctx, r, &resp)
+	return resp, err
+}
+
+// DeleteScheduledMessages deletes all the scheduled and undelivered messages from the sending queue of a domain,
+// e.g. messages sent with SetDeliveryTime or SetSTOPeriod.
+// Messages already being delivered are not affected.
+// The API cannot cancel or list single scheduled messages, only the whole queue of the domain.
+func (mg *Client) DeleteScheduledMessages(ctx context.Context, domain string) error {
+	r := newHTTPRequest(generateApiV3UrlWithDomain(mg, envelopesEndpoint, domain))
+	r.setClient(mg.HTTPClient())
+	r.setBasicAuth(basicAuthUser, mg.APIKey())
+	_, err := makeDeleteRequest(ctx, r)
+	return err
+}
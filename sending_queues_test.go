@@ -0,0 +1,40 @@
+package mailgun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDomainSendingQueues(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	err := mg.SetAPIBase(server.URL())
+	require.NoError(t, err)
+
+	queues, err := mg.GetDomainSendingQueues(context.Background(), testDomain)
+	require.NoError(t, err)
+	assert.False(t, queues.Regular.IsDisabled)
+	assert.False(t, queues.Scheduled.IsDisabled)
+}
+
+func TestDeleteScheduledMessages(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	err := mg.SetAPIBase(server.URL())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	m := mailgun.NewMessage(testDomain, fromUser, exampleSubject, exampleText, "user@example.com")
+	m.SetDeliveryTime(time.Now().Add(time.Hour))
+	resp, err := mg.Send(ctx, m)
+	require.NoError(t, err)
+	scheduled := server.ScheduledMessages(testDomain)
+	require.NotEmpty(t, scheduled)
+	assert.Equal(t, "<"+scheduled[len(scheduled)-1]+">", resp.ID)
+
+	require.NoError(t, mg.DeleteScheduledMessages(ctx, testDomain))
+	assert.Empty(t, server.ScheduledMessages(testDomain))
+}
@@ -0,0 +1,166 @@
+package mailgun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/mailgun/mailgun-go/v5/webhooks"
+)
+
+// StoredMessageNotification is a stored message notified by a store(notify="...") route action,
+// along with the message retrieved from the storage.
+type StoredMessageNotification struct {
+	NotifiedMessage
+	// Message is the parsed MIME message. Its content IDs are resolved with Message.ResolveContentIDs(ContentIDMap).
+	Message *mtypes.MIMEMessage
+	// Raw is the raw MIME message.
+	Raw string
+}
+
+// StoredMessageHandlerFunc handles a stored message notification.
+type StoredMessageHandlerFunc func(ctx context.Context, n *StoredMessageNotification) error
+
+// StoredMessageHandlerOptions modifies the behavior of the StoredMessageHandler.
+type StoredMessageHandlerOptions struct {
+	// MaxBodySize is the maximum size of the notification body. Defaults to webhooks.DefaultMaxInboundBodySize.
+	MaxBodySize int64
+	// Archive, if set, is called once the message is handled, e.g. to copy the raw message to long-term storage.
+	// The notification is retried if it fails.
+	Archive StoredMessageHandlerFunc
+	// Delete deletes the message from the storage once it is handled and archived.
+	Delete bool
+	// OnError is called with the error of every failed request, e.g. for logging, and with the deletion errors.
+	OnError func(r *http.Request, err error)
+}
+
+// StoredMessageHandler is an http.Handler receiving the notifications of stored messages. It verifies
+// the signature of the notification, retrieves the message from the storage and parses it, then passes
+// it to the handler function. It responds as webhooks.Handler does. The notifications whose message URL
+// is neither on the API host of mg nor on a Mailgun storage host are rejected.
+//
+//	v := webhooks.NewVerifier("WEBHOOK_SIGNING_KEY")
+//	http.Handle("/stored", mailgun.NewStoredMessageHandler(mg, v,
+//		func(ctx context.Context, n *mailgun.StoredMessageNotification) error {
+//			fmt.Printf("%s: %d attachments\n", n.Subject, len(n.Message.Attachments))
+//			return nil
+//		}, &mailgun.StoredMessageHandlerOptions{Delete: true}))
+type StoredMessageHandler struct {
+	mg       Mailgun
+	verifier *webhooks.Verifier
+	handle   StoredMessageHandlerFunc
+	opts     StoredMessageHandlerOptions
+}
+
+// NewStoredMessageHandler creates a handler verifying the signatures with the verifier, retrieving
+// the notified messages with mg and passing them to the handler function.
+func NewStoredMessageHandler(mg Mailgun, verifier *webhooks.Verifier, handle StoredMessageHandlerFunc,
+	opts *StoredMessageHandlerOptions,
+) *StoredMessageHandler {
+	h := StoredMessageHandler{
+		mg:       mg,
+		verifier: verifier,
+		handle:   handle,
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.MaxBodySize <= 0 {
+		h.opts.MaxBodySize = webhooks.DefaultMaxInboundBodySize
+	}
+	return &h
+}
+
+func (h *StoredMessageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		webhooks.Fail(w, r, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method), h.opts.OnError)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.opts.MaxBodySize)
+	inbound, err := webhooks.ParseInboundMessage(r, webhooks.DefaultMaxInboundMemory)
+	if r.MultipartForm != nil {
+		defer func() { _ = r.MultipartForm.RemoveAll() }()
+	}
+	if err != nil {
+		webhooks.Fail(w, r, http.StatusNotAcceptable, err, h.opts.OnError)
+		return
+	}
+
+	if err := h.verifier.VerifyContext(r.Context(), inbound.Signature); err != nil {
+		webhooks.Fail(w, r, webhooks.StatusCode(err), err, h.opts.OnError)
+		return
+	}
+
+	n := StoredMessageNotification{NotifiedMessage: ExtractNotifiedMessage(r.PostForm)}
+	if n.MessageURL == "" {
+		webhooks.Fail(w, r, http.StatusNotAcceptable, errors.New("stored message notification has no message-url"),
+			h.opts.OnError)
+		return
+	}
+	// The message is retrieved with the API key: never send it to a host that is not Mailgun's.
+	if !isStorageURL(h.mg.APIBase(), n.MessageURL) {
+		webhooks.Fail(w, r, http.StatusNotAcceptable,
+			fmt.Errorf("message-url '%s' is not a Mailgun storage URL", n.MessageURL), h.opts.OnError)
+		return
+	}
+
+	raw, err := h.mg.GetStoredMessageRaw(r.Context(), n.MessageURL)
+	if err != nil {
+		status := http.StatusServiceUnavailable
+		if GetStatusFromErr(err) == http.StatusNotFound {
+			// The message expired from the storage, there is no point in retrying.
+			status = http.StatusNotAcceptable
+		}
+		webhooks.Fail(w, r, status, h.verifier.ForgetRetried(r.Context(), inbound.Signature, status,
+			fmt.Errorf("while retrieving stored message '%s': %w", n.MessageURL, err)), h.opts.OnError)
+		return
+	}
+	n.Raw = raw.BodyMime
+	if n.Message, err = webhooks.ParseMIME(strings.NewReader(raw.BodyMime)); err != nil {
+		webhooks.Fail(w, r, http.StatusNotAcceptable,
+			fmt.Errorf("while parsing stored message '%s': %w", n.MessageURL, err), h.opts.OnError)
+		return
+	}
+
+	if err := h.handle(r.Context(), &n); err != nil {
+		status := webhooks.StatusCode(err)
+		webhooks.Fail(w, r, status, h.verifier.ForgetRetried(r.Context(), inbound.Signature, status,
+			fmt.Errorf("while handling stored message '%s': %w", n.MessageURL, err)), h.opts.OnError)
+		return
+	}
+	if h.opts.Archive != nil {
+		if err := h.opts.Archive(r.Context(), &n); err != nil {
+			status := webhooks.StatusCode(err)
+			webhooks.Fail(w, r, status, h.verifier.ForgetRetried(r.Context(), inbound.Signature, status,
+				fmt.Errorf("while archiving stored message '%s': %w", n.MessageURL, err)), h.opts.OnError)
+			return
+		}
+	}
+	if h.opts.Delete {
+		// The message is handled: report the error without retrying the notification.
+		if err := h.mg.DeleteStoredMessage(r.Context(), n.MessageURL); err != nil && h.opts.OnError != nil {
+			h.opts.OnError(r, fmt.Errorf("while deleting stored message '%s': %w", n.MessageURL, err))
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// isStorageURL reports whether the message URL is on the host of the API base or, over HTTPS,
+// on a Mailgun storage host such as storage-us-east4.api.mailgun.net.
+func isStorageURL(apiBase, messageURL string) bool {
+	u, err := url.Parse(messageURL)
+	if err != nil || u.User != nil {
+		return false
+	}
+	if base, err := url.Parse(apiBase); err == nil && u.Scheme == base.Scheme && strings.EqualFold(u.Host, base.Host) {
+		return true
+	}
+	host := strings.ToLower(u.Host)
+	return u.Scheme == "https" && (host == "mailgun.net" || strings.HasSuffix(host, ".mailgun.net"))
+}
@@ -3,8 +3,11 @@ package mailgun
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/mailgun/mailgun-go/v5/webhooks"
 )
 
 // GetStoredMessage retrieves information about a received e-mail message.
@@ -37,6 +40,13 @@ func (mg *Client) ReSend(ctx context.Context, url string, recipients ...string)
 		payload.addValue("to", to)
 	}
 
+	if mg.sandbox != nil {
+		err := mg.sandbox.apply(payload)
+		if err != nil {
+			return resp, err
+		}
+	}
+
 	err := postResponseFromJSON(ctx, r, payload, &resp)
 	if err != nil {
 		return resp, err
@@ -73,3 +83,27 @@ func (mg *Client) GetStoredAttachment(ctx context.Context, url string) ([]byte,
 
 	return response.Data, err
 }
+
+// FetchStoredMessage retrieves the raw MIME body of a received e-mail message and parses it
+// into its headers, bodies and attachments. The message is retrieved with the API key, so the URL,
+// e.g. from a notification, must be on the API host or a Mailgun storage host.
+func (mg *Client) FetchStoredMessage(ctx context.Context, url string) (*mtypes.MIMEMessage, error) {
+	if !isStorageURL(mg.APIBase(), url) {
+		return nil, fmt.Errorf("message URL '%s' is not a Mailgun storage URL", url)
+	}
+	raw, err := mg.GetStoredMessageRaw(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks.ParseMIME(strings.NewReader(raw.BodyMime))
+}
+
+// DeleteStoredMessage deletes a received e-mail message from the storage.
+func (mg *Client) DeleteStoredMessage(ctx context.Context, url string) error {
+	r := newHTTPRequest(url)
+	r.setClient(mg.HTTPClient())
+	r.setBasicAuth(basicAuthUser, mg.APIKey())
+
+	_, err := makeDeleteRequest(ctx, r)
+	return err
+}
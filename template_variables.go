@@ -0,0 +1,424 @@
+package mailgun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// TemplatePlaceholders lists the variables referenced by a stored template version.
+// Use ParseTemplatePlaceholders with a cached mtypes.TemplateVersion,
+// or GetTemplatePlaceholders to fetch the version from the API.
+type TemplatePlaceholders struct {
+	// Variables are the top-level template variables,
+	// e.g. `user` for `{{user.name}}` (handlebars) or `User` for `{{.User.Name}}` (go).
+	Variables []string
+	// RecipientVariables are the recipient variables referenced as `%recipient.name%`.
+	RecipientVariables []string
+}
+
+// TemplateVariablesError is returned when the fields of a variables struct
+// do not match the placeholders of a template.
+type TemplateVariablesError struct {
+	// Missing are the placeholders that have no corresponding struct field.
+	Missing []string
+	// Unused are the struct fields that are not referenced by the template.
+	Unused []string
+}
+
+func (e *TemplateVariablesError) Error() string {
+	var parts []string
+	if len(e.Missing) != 0 {
+		parts = append(parts, "missing: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unused) != 0 {
+		parts = append(parts, "unused: "+strings.Join(e.Unused, ", "))
+	}
+	return "template variables mismatch (" + strings.Join(parts, "; ") + ")"
+}
+
+// GetTemplatePlaceholders fetches a template version and parses its placeholders.
+// If tag is empty, the active version of the template is used.
+func (mg *Client) GetTemplatePlaceholders(ctx context.Context, domain, templateName, tag string) (TemplatePlaceholders, error) {
+	var (
+		version mtypes.TemplateVersion
+		err     error
+	)
+	if tag == "" {
+		var tmpl mtypes.Template
+		tmpl, err = mg.GetTemplate(ctx, domain, templateName)
+		version = tmpl.Version
+	} else {
+		version, err = mg.GetTemplateVersion(ctx, domain, templateName, tag)
+	}
+	if err != nil {
+		return TemplatePlaceholders{}, err
+	}
+
+	return ParseTemplatePlaceholders(version)
+}
+
+// ParseTemplatePlaceholders extracts the placeholders of a template version.
+// The handlebars engine is assumed if the version has no engine set.
+func ParseTemplatePlaceholders(version mtypes.TemplateVersion) (TemplatePlaceholders, error) {
+	var (
+		vars []string
+		err  error
+	)
+	switch version.Engine {
+	case mtypes.TemplateEngineHandlebars, "":
+		vars, err = parseHandlebarsVariables(version.Template)
+	case mtypes.TemplateEngineGo:
+		vars, err = parseGoTemplateVariables(version.Template)
+	default:
+		return TemplatePlaceholders{}, fmt.Errorf("unsupported template engine '%s'", version.Engine)
+	}
+	if err != nil {
+		return TemplatePlaceholders{}, err
+	}
+
+	var rcptVars []string
+	for _, m := range recipientVariableRe.FindAllStringSubmatch(version.Template, -1) {
+		rcptVars = append(rcptVars, m[1])
+	}
+
+	return TemplatePlaceholders{
+		Variables:          uniqueSorted(vars),
+		RecipientVariables: uniqueSorted(rcptVars),
+	}, nil
+}
+
+// CheckTemplateVariables compares the fields of T with the template variables.
+// It returns a *TemplateVariablesError if any variable is missing or any field is unused.
+func CheckTemplateVariables[T any](p TemplatePlaceholders) error {
+	return checkVariables[T](p.Variables, true)
+}
+
+// CheckRecipientVariables compares the fields of T with the recipient variables.
+// It returns a *TemplateVariablesError if any variable is missing or any field is unused.
+func CheckRecipientVariables[T any](p TemplatePlaceholders) error {
+	return checkVariables[T](p.RecipientVariables, true)
+}
+
+// SetTemplateVariables sets the fields of vars as template variables of the message.
+// Field names are taken from `json` tags.
+// It returns a *TemplateVariablesError if the template references a variable
+// which is not a field of T. On any error the message is left untouched.
+func SetTemplateVariables[T any](m *PlainMessage, p TemplatePlaceholders, vars T) error {
+	if err := checkVariables[T](p.Variables, false); err != nil {
+		return err
+	}
+
+	// Encode every value before applying any of them.
+	values, err := structToMap(vars)
+	if err != nil {
+		return err
+	}
+	if m.templateVariables == nil {
+		m.templateVariables = make(map[string]any, len(values))
+	}
+	maps.Copy(m.templateVariables, values)
+	return nil
+}
+
+// AddRecipientAndTemplateVariables appends a receiver to the To: header of a message
+// and attaches the fields of vars as its recipient variables.
+// It returns a *TemplateVariablesError and leaves the message untouched
+// if the template references a recipient variable which is not a field of T.
+func AddRecipientAndTemplateVariables[T any](m *PlainMessage, p TemplatePlaceholders, recipient string, vars T) error {
+	if err := checkVariables[T](p.RecipientVariables, false); err != nil {
+		return err
+	}
+
+	values, err := structToMap(vars)
+	if err != nil {
+		return err
+	}
+	return m.AddRecipientAndVariables(recipient, values)
+}
+
+func checkVariables[T any](placeholders []string, reportUnused bool) error {
+	fields, err := jsonFieldNames(reflect.TypeFor[T]())
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f] = true
+	}
+	used := make(map[string]bool, len(placeholders))
+	for _, p := range placeholders {
+		used[p] = true
+	}
+
+	var verr TemplateVariablesError
+	for _, p := range placeholders {
+		if !known[p] {
+			verr.Missing = append(verr.Missing, p)
+		}
+	}
+	if reportUnused {
+		for _, f := range fields {
+			if !used[f] {
+				verr.Unused = append(verr.Unused, f)
+			}
+		}
+	}
+
+	if len(verr.Missing) != 0 || len(verr.Unused) != 0 {
+		return &verr
+	}
+	return nil
+}
+
+// jsonFieldNames returns the sorted JSON names of the fields of a struct type,
+// including the fields of embedded structs.
+func jsonFieldNames(typ reflect.Type) ([]string, error) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("template variables must be a struct, got %s", typ)
+	}
+
+	var names []string
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded, err := jsonFieldNames(ft)
+				if err != nil {
+					return nil, err
+				}
+				names = append(names, embedded...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+
+	return uniqueSorted(names), nil
+}
+
+func structToMap(v any) (map[string]any, error) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(j, &m); err != nil {
+		return nil, fmt.Errorf("template variables must encode to a JSON object: %w", err)
+	}
+	return m, nil
+}
+
+var (
+	recipientVariableRe = regexp.MustCompile(`%recipient\.([A-Za-z0-9_-]+)%`)
+	mustacheRe          = regexp.MustCompile(`(?s){{{?(.*?)}?}}`)
+	goActionRe          = regexp.MustCompile(`(?s){{(.*?)}}`)
+	goFieldRe           = regexp.MustCompile(`(?:^|[\s(|,=])(\$?)\.([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// handlebarsScopes are the block helpers which change the context of the block.
+var handlebarsScopes = map[string]bool{"each": true, "with": true}
+
+// parseHandlebarsVariables returns the top-level variables referenced in the root context.
+// Variables inside #each and #with blocks are relative to the block and are ignored,
+// unless they are referenced through @root.
+func parseHandlebarsVariables(tmpl string) ([]string, error) {
+	var (
+		vars  []string
+		stack []string
+		depth int
+	)
+
+	addPath := func(path string) {
+		if rest, ok := strings.CutPrefix(path, "@root."); ok {
+			path = rest
+		} else if depth > 0 {
+			return
+		}
+		// {{this}}, {{.}}, {{this.name}} and {{./name}} refer to the context itself.
+		if path == "" || path == "this" || path == "." || strings.HasPrefix(path, "@") ||
+			strings.HasPrefix(path, "../") || strings.HasPrefix(path, "this.") ||
+			strings.HasPrefix(path, "this/") || strings.HasPrefix(path, "./") {
+			return
+		}
+		name, _, _ := strings.Cut(path, ".")
+		if name == "" || isHandlebarsLiteral(name) {
+			return
+		}
+		vars = append(vars, name)
+	}
+
+	addArgs := func(args []string) {
+		for _, arg := range args {
+			// {{#each items as |item|}}
+			if arg == "as" {
+				return
+			}
+			if _, value, ok := strings.Cut(arg, "="); ok {
+				arg = value
+			}
+			// {{helper (format date) }}: the first word of a subexpression is a helper.
+			if strings.HasPrefix(arg, "(") {
+				continue
+			}
+			addPath(strings.TrimRight(arg, ")"))
+		}
+	}
+
+	for _, m := range mustacheRe.FindAllStringSubmatch(tmpl, -1) {
+		expr := strings.TrimSpace(strings.Trim(m[1], "~"))
+		if expr == "" || strings.HasPrefix(expr, "!") || strings.HasPrefix(expr, ">") {
+			continue
+		}
+
+		switch expr[0] {
+		case '#', '^':
+			fields := strings.Fields(expr[1:])
+			if len(fields) == 0 {
+				continue
+			}
+			helper := fields[0]
+			// Arguments are evaluated in the outer context.
+			addArgs(fields[1:])
+			if len(fields) == 1 && expr[0] == '^' {
+				// {{^name}} is an inverted section on a variable.
+				addPath(helper)
+			}
+			stack = append(stack, helper)
+			if handlebarsScopes[helper] {
+				depth++
+			}
+		case '/':
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("unexpected closing block '{{%s}}'", expr)
+			}
+			helper := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if handlebarsScopes[helper] {
+				depth--
+			}
+		default:
+			fields := strings.Fields(expr)
+			switch {
+			case fields[0] == "else":
+				// {{else if cond}}
+				if len(fields) > 2 {
+					addArgs(fields[2:])
+				}
+			case len(fields) == 1:
+				addPath(fields[0])
+			default:
+				// {{helper arg1 arg2}}
+				addArgs(fields[1:])
+			}
+		}
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("unclosed block '{{#%s}}'", stack[len(stack)-1])
+	}
+
+	return vars, nil
+}
+
+func isHandlebarsLiteral(s string) bool {
+	if s == "true" || s == "false" || s == "null" || s == "undefined" {
+		return true
+	}
+	c := s[0]
+	return c == '"' || c == '\'' || c == '-' || (c >= '0' && c <= '9')
+}
+
+// parseGoTemplateVariables returns the top-level fields of dot referenced in the root context.
+// Fields inside range and with blocks are relative to the block and are ignored,
+// unless they are referenced through $.
+func parseGoTemplateVariables(tmpl string) ([]string, error) {
+	var (
+		vars  []string
+		stack []string
+		depth int
+	)
+
+	for _, m := range goActionRe.FindAllStringSubmatch(tmpl, -1) {
+		action := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(m[1], "-"), "-"))
+		if strings.HasPrefix(action, "/*") {
+			continue
+		}
+
+		keyword, _, _ := strings.Cut(action, " ")
+		switch keyword {
+		case "if", "range", "with", "block", "define":
+			stack = append(stack, keyword)
+		case "end":
+			if len(stack) == 0 {
+				return nil, errors.New("unexpected {{end}}")
+			}
+			if kw := stack[len(stack)-1]; kw == "range" || kw == "with" {
+				depth--
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		// The pipeline of range and with is evaluated in the outer context.
+		for _, f := range goFieldRe.FindAllStringSubmatch(" "+action, -1) {
+			if f[1] == "$" || depth == 0 {
+				vars = append(vars, f[2])
+			}
+		}
+
+		if keyword == "range" || keyword == "with" {
+			depth++
+		}
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("unclosed {{%s}}", stack[len(stack)-1])
+	}
+
+	return vars, nil
+}
+
+func uniqueSorted(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(s))
+	result := make([]string, 0, len(s))
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
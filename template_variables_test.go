@@ -0,0 +1,162 @@
+package mailgun_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplatePlaceholders(t *testing.T) {
+	t.Run("handlebars", func(t *testing.T) {
+		p, err := mailgun.ParseTemplatePlaceholders(mtypes.TemplateVersion{
+			Template: `Hi {{user.name}}, {{{signature}}}
+{{#if premium}}Thanks!{{else if trial}}Upgrade{{/if}}
+{{#each items as |item|}}{{item.title}} {{title}} {{@root.currency}}{{/each}}
+{{!-- {{commented}} --}}{{formatDate sentAt "short"}} %recipient.code%`,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"currency", "items", "premium", "sentAt", "signature", "trial", "user"}, p.Variables)
+		assert.Equal(t, []string{"code"}, p.RecipientVariables)
+	})
+
+	t.Run("handlebars context", func(t *testing.T) {
+		p, err := mailgun.ParseTemplatePlaceholders(mtypes.TemplateVersion{
+			Template: `{{.}} {{./name}} {{this}} {{this/name}} {{.hidden}} {{title}}`,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"title"}, p.Variables)
+	})
+
+	t.Run("handlebars subexpression", func(t *testing.T) {
+		p, err := mailgun.ParseTemplatePlaceholders(mtypes.TemplateVersion{
+			Template: `{{#if (gt total limit)}}{{link (concat baseUrl path) label=(upper name)}}{{/if}}`,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"baseUrl", "limit", "name", "path", "total"}, p.Variables)
+	})
+
+	t.Run("go", func(t *testing.T) {
+		p, err := mailgun.ParseTemplatePlaceholders(mtypes.TemplateVersion{
+			Engine:   mtypes.TemplateEngineGo,
+			Template: `Hi {{.User.Name}}{{if .Premium}}!{{end}}{{range .Items}}{{.Title}} {{$.Currency}}{{end}}{{/* .Hidden */}}`,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Currency", "Items", "Premium", "User"}, p.Variables)
+	})
+
+	t.Run("unclosed block", func(t *testing.T) {
+		_, err := mailgun.ParseTemplatePlaceholders(mtypes.TemplateVersion{Template: "{{#if a}}"})
+		require.EqualError(t, err, "unclosed block '{{#if}}'")
+	})
+}
+
+type welcomeUser struct {
+	Name string `json:"name"`
+}
+
+type welcomeVars struct {
+	User      welcomeUser `json:"user"`
+	Premium   bool        `json:"premium"`
+	Signature string      `json:"signature,omitempty"`
+	internal  string
+}
+
+type welcomeRecipientVars struct {
+	Code string `json:"code"`
+}
+
+func TestTemplateVariables(t *testing.T) {
+	p, err := mailgun.ParseTemplatePlaceholders(mtypes.TemplateVersion{
+		Template: `Hi {{user.name}}{{#if premium}}!{{/if}} {{signature}} %recipient.code%`,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, mailgun.CheckTemplateVariables[welcomeVars](p))
+	require.NoError(t, mailgun.CheckRecipientVariables[welcomeRecipientVars](p))
+
+	m := mailgun.NewMessage(testDomain, fromUser, exampleSubject, "")
+	m.SetTemplate("welcome")
+	err = mailgun.SetTemplateVariables(m, p, welcomeVars{User: welcomeUser{Name: "Joe"}, Premium: true, internal: "x"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"user": map[string]any{"name": "Joe"}, "premium": true}, m.TemplateVariables())
+
+	err = mailgun.AddRecipientAndTemplateVariables(m, p, "joe@example.com", welcomeRecipientVars{Code: "42"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"joe@example.com"}, m.To())
+	assert.Equal(t, map[string]map[string]any{"joe@example.com": {"code": "42"}}, m.RecipientVariables())
+}
+
+func TestTemplateVariablesMismatch(t *testing.T) {
+	p, err := mailgun.ParseTemplatePlaceholders(mtypes.TemplateVersion{
+		Template: `Hi {{user.name}}, your order {{orderId}} %recipient.first%`,
+	})
+	require.NoError(t, err)
+
+	err = mailgun.CheckTemplateVariables[welcomeVars](p)
+	var verr *mailgun.TemplateVariablesError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []string{"orderId"}, verr.Missing)
+	assert.Equal(t, []string{"premium", "signature"}, verr.Unused)
+
+	m := mailgun.NewMessage(testDomain, fromUser, exampleSubject, "")
+	err = mailgun.SetTemplateVariables(m, p, welcomeVars{})
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []string{"orderId"}, verr.Missing)
+	assert.Empty(t, verr.Unused)
+	assert.Nil(t, m.TemplateVariables())
+
+	err = mailgun.AddRecipientAndTemplateVariables(m, p, "joe@example.com", welcomeRecipientVars{})
+	require.EqualError(t, err, "template variables mismatch (missing: first)")
+	assert.Empty(t, m.To())
+}
+
+func TestSetTemplateVariablesEncodeError(t *testing.T) {
+	type vars struct {
+		Name string    `json:"name"`
+		Done chan bool `json:"done"`
+	}
+	p, err := mailgun.ParseTemplatePlaceholders(mtypes.TemplateVersion{Template: `Hi {{name}}`})
+	require.NoError(t, err)
+
+	m := mailgun.NewMessage(testDomain, fromUser, exampleSubject, "")
+	require.NoError(t, m.AddTemplateVariable("greeting", "Hi"))
+	err = mailgun.SetTemplateVariables(m, p, vars{Name: "Joe", Done: make(chan bool)})
+	require.Error(t, err)
+	assert.Equal(t, map[string]any{"greeting": "Hi"}, m.TemplateVariables())
+}
+
+func TestGetTemplatePlaceholders(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	err := mg.SetAPIBase(server.URL())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	tmpl := mtypes.Template{
+		Name: randomString(10, "Mailgun-go-TestGetTemplatePlaceholders-"),
+	}
+	require.NoError(t, mg.CreateTemplate(ctx, testDomain, &tmpl))
+	defer func() {
+		require.NoError(t, mg.DeleteTemplate(ctx, testDomain, tmpl.Name))
+	}()
+
+	version := mtypes.TemplateVersion{
+		Tag:      "v1",
+		Template: "{{.Name}}",
+		Active:   true,
+		Engine:   mtypes.TemplateEngineGo,
+	}
+	require.NoError(t, mg.AddTemplateVersion(ctx, testDomain, tmpl.Name, &version))
+
+	p, err := mg.GetTemplatePlaceholders(ctx, testDomain, tmpl.Name, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Name"}, p.Variables)
+
+	// The active version is used if no tag is given.
+	p, err = mg.GetTemplatePlaceholders(ctx, testDomain, tmpl.Name, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Name"}, p.Variables)
+}
@@ -0,0 +1,163 @@
+package mailgun
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/mailgun/mailgun-go/v5/internal/msgid"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/mailgun/mailgun-go/v5/webhooks"
+)
+
+// Thread identifies the conversation a received message belongs to. Message IDs are
+// without their angle brackets, compare them with strings.Trim(resp.ID, "<>") for the ID returned by Send().
+type Thread struct {
+	// MessageID is the Message-Id header of the message.
+	MessageID string
+	// InReplyTo is the In-Reply-To header, the ID of the message replied to.
+	InReplyTo string
+	// References are the IDs of the References header, from the first message of the conversation to the parent.
+	References []string
+	// ReplyToken is the token of the plus-addressed recipient, see ReplyToAddress.
+	ReplyToken string
+	// Key is the ID of the first message of the conversation: the first reference, else the message replied to,
+	// else the message itself. For a reply to a message sent with Send(), it is the ID of the sent message.
+	Key string
+}
+
+// ConversationKey returns the outbound message ID of the reply token, as resolved by resolveToken, or Key if
+// the message has no reply token or it cannot be resolved. It finds the conversation of the replies from clients
+// that drop the In-Reply-To and References headers. resolveToken may be nil.
+func (t *Thread) ConversationKey(resolveToken func(token string) (messageID string, ok bool)) string {
+	if t.ReplyToken != "" && resolveToken != nil {
+		if id, ok := resolveToken(t.ReplyToken); ok {
+			return msgid.Trim(id)
+		}
+	}
+	return t.Key
+}
+
+// NewThread returns the thread of a message from its headers, as in the message-headers field, and its recipients.
+// Only the first value of each header counts.
+func NewThread(headers [][]string, recipients ...string) Thread {
+	var t Thread
+	for _, h := range headers {
+		if len(h) < 2 {
+			continue
+		}
+		switch strings.ToLower(h[0]) {
+		case "message-id":
+			if t.MessageID == "" {
+				t.MessageID = msgid.Trim(h[1])
+			}
+		case "in-reply-to":
+			if t.InReplyTo == "" {
+				t.InReplyTo = msgid.Trim(h[1])
+			}
+		case "references":
+			if t.References == nil {
+				t.References = msgid.Parse(h[1])
+			}
+		}
+	}
+
+recipients:
+	for _, recipient := range recipients {
+		for _, address := range strings.Split(recipient, ",") {
+			if t.ReplyToken = ReplyToken(address); t.ReplyToken != "" {
+				break recipients
+			}
+		}
+	}
+
+	switch {
+	case len(t.References) > 0:
+		t.Key = t.References[0]
+	case t.InReplyTo != "":
+		t.Key = t.InReplyTo
+	default:
+		t.Key = t.MessageID
+	}
+	return t
+}
+
+// Thread returns the thread of the forwarded message, from HeaderList, else from MessageHeaders.
+func (m *ForwardedMessage) Thread() Thread {
+	return NewThread(headerList(m.HeaderList, m.MessageHeaders), m.Recipient)
+}
+
+// Thread returns the thread of the notified message, from HeaderList, else from MessageHeaders.
+func (m *NotifiedMessage) Thread() Thread {
+	return NewThread(headerList(m.HeaderList, m.MessageHeaders), m.Recipient)
+}
+
+// headerList returns the ordered headers if set, else the headers of the map, e.g. for a message
+// not created by ExtractForwardedMessage or ExtractNotifiedMessage.
+func headerList(list [][]string, headers map[string]string) [][]string {
+	if list != nil {
+		return list
+	}
+	list = make([][]string, 0, len(headers))
+	for name, value := range headers {
+		list = append(list, []string{name, value})
+	}
+	return list
+}
+
+// StoredMessageThread returns the thread of the stored message.
+func StoredMessageThread(m *mtypes.StoredMessage) Thread {
+	return NewThread(m.MessageHeaders, m.Recipients)
+}
+
+// InboundMessageThread returns the thread of the message received by a webhooks.InboundHandler.
+func InboundMessageThread(m *webhooks.InboundMessage) Thread {
+	headers := make([][]string, 0, len(m.Headers))
+	for _, h := range m.Headers {
+		headers = append(headers, []string{h.Name, h.Value})
+	}
+	return NewThread(headers, m.Recipient)
+}
+
+var replyTokenPattern = regexp.MustCompile(`^[A-Za-z0-9._=-]+$`)
+
+// ReplyToAddress embeds the token in the address with plus addressing, e.g. "support+token@example.com" for
+// "support@example.com", to set as the Reply-To header of a sent message. Store the token with the ID returned
+// by Send() to find the conversation of the replies, see Thread.ConversationKey.
+//
+//	replyTo, err := mailgun.ReplyToAddress("Support <support@example.com>", ticketID)
+//	m.SetReplyTo(replyTo)
+func ReplyToAddress(address, token string) (string, error) {
+	if !replyTokenPattern.MatchString(token) {
+		return "", fmt.Errorf("invalid reply token '%s': only letters, digits and ._=- are allowed", token)
+	}
+	addr, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("while parsing reply-to address: %w", err)
+	}
+	local, domain, ok := strings.Cut(addr.Address, "@")
+	if !ok {
+		return "", errors.New("reply-to address has no domain")
+	}
+	local, _, _ = strings.Cut(local, "+")
+	addr.Address = local + "+" + token + "@" + domain
+	return addr.String(), nil
+}
+
+// ReplyToken returns the token of a plus-addressed address, see ReplyToAddress, or "" if there is none.
+func ReplyToken(address string) string {
+	if addr, err := mail.ParseAddress(address); err == nil {
+		address = addr.Address
+	}
+	local, _, ok := strings.Cut(strings.TrimSpace(address), "@")
+	if !ok {
+		return ""
+	}
+	_, token, ok := strings.Cut(local, "+")
+	if !ok || !replyTokenPattern.MatchString(token) {
+		return ""
+	}
+	return token
+}
@@ -0,0 +1,96 @@
+package mailgun_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/mailgun/mailgun-go/v5/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplyToAddress(t *testing.T) {
+	addr, err := mailgun.ReplyToAddress("Support <support@example.com>", "ticket-42")
+	require.NoError(t, err)
+	assert.Equal(t, `"Support" <support+ticket-42@example.com>`, addr)
+	assert.Equal(t, "ticket-42", mailgun.ReplyToken(addr))
+
+	// An existing token is replaced.
+	addr, err = mailgun.ReplyToAddress("support+old@example.com", "new")
+	require.NoError(t, err)
+	assert.Equal(t, "<support+new@example.com>", addr)
+
+	_, err = mailgun.ReplyToAddress("support@example.com", "not a token")
+	assert.Error(t, err)
+	_, err = mailgun.ReplyToAddress("not an address", "token")
+	assert.Error(t, err)
+	assert.Empty(t, mailgun.ReplyToken("support@example.com"))
+}
+
+func TestThread(t *testing.T) {
+	form := url.Values{
+		"recipient": {"support+ticket-42@example.com"},
+		"message-headers": {`[
+			["Message-Id", "<reply-2@example.net>"],
+			["In-Reply-To", "<reply-1@example.com>"],
+			["References", "<sent@example.com>\r\n <reply-1@example.com>"]
+		]`},
+	}
+	forwarded := mailgun.ExtractForwardedMessage(form)
+	thread := forwarded.Thread()
+	assert.Equal(t, mailgun.Thread{
+		MessageID:  "reply-2@example.net",
+		InReplyTo:  "reply-1@example.com",
+		References: []string{"sent@example.com", "reply-1@example.com"},
+		ReplyToken: "ticket-42",
+		Key:        "sent@example.com",
+	}, thread)
+
+	tickets := map[string]string{"ticket-42": "<ticket@example.com>"}
+	resolve := func(token string) (string, bool) {
+		id, ok := tickets[token]
+		return id, ok
+	}
+	assert.Equal(t, "ticket@example.com", thread.ConversationKey(resolve))
+	assert.Equal(t, "sent@example.com", thread.ConversationKey(nil))
+
+	// Without References, the key is the message replied to, else the message itself.
+	notified := mailgun.ExtractNotifiedMessage(url.Values{
+		"message-headers": {`[["Message-Id", "<reply@example.net>"], ["In-Reply-To", "<sent@example.com>"]]`},
+	})
+	assert.Equal(t, "sent@example.com", notified.Thread().Key)
+
+	stored := mtypes.StoredMessage{
+		Recipients:     "someone@example.com, support+ticket-7@example.com",
+		MessageHeaders: [][]string{{"Message-Id", "<first@example.net>"}},
+	}
+	thread = mailgun.StoredMessageThread(&stored)
+	assert.Equal(t, "first@example.net", thread.Key)
+	assert.Equal(t, "ticket-7", thread.ReplyToken)
+	assert.Equal(t, "first@example.net", thread.ConversationKey(resolve))
+
+	inbound := webhooks.InboundMessage{
+		Recipient: "support+ticket-42@example.com",
+		Headers: webhooks.Headers{
+			{Name: "Message-ID", Value: "<reply@example.net>"},
+			{Name: "References", Value: "<sent@example.com>"},
+		},
+	}
+	thread = mailgun.InboundMessageThread(&inbound)
+	assert.Equal(t, "sent@example.com", thread.Key)
+	assert.Equal(t, "ticket@example.com", thread.ConversationKey(resolve))
+}
+
+func TestThreadRepeatedHeaders(t *testing.T) {
+	// The first value of a repeated header counts, as in the ordered message-headers.
+	forwarded := mailgun.ExtractForwardedMessage(url.Values{
+		"message-headers": {`[
+			["Message-Id", "<first@example.net>"],
+			["Message-Id", "<second@example.net>"]
+		]`},
+	})
+	assert.Equal(t, "<second@example.net>", forwarded.MessageHeaders["Message-Id"])
+	assert.Equal(t, "first@example.net", forwarded.Thread().MessageID)
+}
@@ -4,14 +4,11 @@ package mailgun
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"crypto/subtle"
-	"encoding/hex"
+	"errors"
 	"fmt"
-	"io"
 
 	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/mailgun/mailgun-go/v5/webhooks"
 )
 
 // ListWebhooks returns the complete set of webhooks configured for your domain.
@@ -20,6 +17,7 @@ func (mg *Client) ListWebhooks(ctx context.Context, domain string) (map[string][
 	r := newHTTPRequest(generateV3DomainsApiUrl(mg, webhooksEndpoint, domain))
 	r.setClient(mg.HTTPClient())
 	r.setBasicAuth(basicAuthUser, mg.APIKey())
+	mg.setOverrideHeaders(r)
 
 	var body mtypes.WebHooksListResponse
 	err := getResponseFromJSON(ctx, r, &body)
@@ -44,6 +42,7 @@ func (mg *Client) CreateWebhook(ctx context.Context, domain, id string, urls []s
 	r := newHTTPRequest(generateV3DomainsApiUrl(mg, webhooksEndpoint, domain))
 	r.setClient(mg.HTTPClient())
 	r.setBasicAuth(basicAuthUser, mg.APIKey())
+	mg.setOverrideHeaders(r)
 	p := newUrlEncodedPayload()
 	p.addValue("id", id)
 	for _, url := range urls {
@@ -58,6 +57,7 @@ func (mg *Client) DeleteWebhook(ctx context.Context, domain, name string) error
 	r := newHTTPRequest(generateV3DomainsApiUrl(mg, webhooksEndpoint, domain) + "/" + name)
 	r.setClient(mg.HTTPClient())
 	r.setBasicAuth(basicAuthUser, mg.APIKey())
+	mg.setOverrideHeaders(r)
 	_, err := makeDeleteRequest(ctx, r)
 	return err
 }
@@ -67,6 +67,7 @@ func (mg *Client) GetWebhook(ctx context.Context, domain, name string) ([]string
 	r := newHTTPRequest(generateV3DomainsApiUrl(mg, webhooksEndpoint, domain) + "/" + name)
 	r.setClient(mg.HTTPClient())
 	r.setBasicAuth(basicAuthUser, mg.APIKey())
+	mg.setOverrideHeaders(r)
 	var body mtypes.WebHookResponse
 	if err := getResponseFromJSON(ctx, r, &body); err != nil {
 		return nil, err
@@ -86,6 +87,7 @@ func (mg *Client) UpdateWebhook(ctx context.Context, domain, name string, urls [
 	r := newHTTPRequest(generateV3DomainsApiUrl(mg, webhooksEndpoint, domain) + "/" + name)
 	r.setClient(mg.HTTPClient())
 	r.setBasicAuth(basicAuthUser, mg.APIKey())
+	mg.setOverrideHeaders(r)
 	p := newUrlEncodedPayload()
 	for _, url := range urls {
 		p.addValue("url", url)
@@ -94,32 +96,80 @@ func (mg *Client) UpdateWebhook(ctx context.Context, domain, name string, urls [
 	return err
 }
 
-// VerifyWebhookSignature - use this method to parse the webhook signature given as JSON in the webhook response
+// VerifyWebhookSignature - use this method to parse the webhook signature given as JSON in the webhook response.
+// The signature is verified with each of the webhook signing keys of the client, see SetWebhookSigningKeys,
+// and the matching key is counted in WebhookKeyMatches.
+// Unlike webhooks.Verifier, it does not check the timestamp and the token of the signature.
 func (mg *Client) VerifyWebhookSignature(sig mtypes.Signature) (verified bool, err error) {
-	webhookSigningKey := mg.WebhookSigningKey()
-	if webhookSigningKey == "" {
+	if mg.webhookVerifier == nil {
 		return false, fmt.Errorf("webhook signing key is not set")
 	}
 
-	h := hmac.New(sha256.New, []byte(webhookSigningKey))
+	err = mg.webhookVerifier.Verify(sig)
+	if errors.Is(err, webhooks.ErrInvalidSignature) {
+		return false, nil
+	}
+	return err == nil, err
+}
 
-	_, err = io.WriteString(h, sig.TimeStamp)
-	if err != nil {
-		return false, err
+// GetWebhookSigningKey returns the HTTP webhook signing key of the account.
+func (mg *Client) GetWebhookSigningKey(ctx context.Context) (mtypes.WebhookSigningKeyResponse, error) {
+	r := newHTTPRequest(generateWebhookSigningKeyApiUrl(mg))
+	r.setClient(mg.HTTPClient())
+	r.setBasicAuth(basicAuthUser, mg.APIKey())
+
+	var resp mtypes.WebhookSigningKeyResponse
+	err := getResponseFromJSON(ctx, r, &resp)
+	return resp, err
+}
+
+// RegenerateWebhookSigningKey replaces the HTTP webhook signing key of the account with a new one.
+// Webhooks are signed with the new key from now on, see RotateWebhookSigningKey.
+func (mg *Client) RegenerateWebhookSigningKey(ctx context.Context) (mtypes.WebhookSigningKeyResponse, error) {
+	r := newHTTPRequest(generateWebhookSigningKeyApiUrl(mg))
+	r.setClient(mg.HTTPClient())
+	r.setBasicAuth(basicAuthUser, mg.APIKey())
+
+	var resp mtypes.WebhookSigningKeyResponse
+	err := postResponseFromJSON(ctx, r, nil, &resp)
+	return resp, err
+}
+
+// RotateWebhookSigningKey regenerates the HTTP webhook signing key of the account and returns the new key.
+// The client and the verifiers verify the signatures with the new key first, and keep accepting the
+// webhooks.MaxPreviousSigningKeys most recent previous keys, as the webhooks signed before the rotation
+// may still be retried. Once the previous key no longer matches (see WebhookKeyMatches and
+// webhooks.Verifier.KeyMatches), retire it with SetWebhookSigningKey and webhooks.Verifier.RetirePreviousKeys.
+//
+// The webhook signing key of the client is fetched first if it is not set.
+func (mg *Client) RotateWebhookSigningKey(ctx context.Context, verifiers ...*webhooks.Verifier) (string, error) {
+	if mg.webhookVerifier == nil {
+		resp, err := mg.GetWebhookSigningKey(ctx)
+		if err != nil {
+			return "", fmt.Errorf("while getting the webhook signing key: %w", err)
+		}
+		mg.SetWebhookSigningKey(resp.SigningKey)
 	}
-	_, err = io.WriteString(h, sig.Token)
+
+	resp, err := mg.RegenerateWebhookSigningKey(ctx)
 	if err != nil {
-		return false, err
+		return "", fmt.Errorf("while regenerating the webhook signing key: %w", err)
+	}
+	if resp.SigningKey == "" {
+		return "", errors.New("the regenerated webhook signing key is empty")
 	}
 
-	calculatedSignature := h.Sum(nil)
-	signature, err := hex.DecodeString(sig.Signature)
-	if err != nil {
-		return false, err
+	if mg.webhookVerifier == nil {
+		mg.SetWebhookSigningKey(resp.SigningKey)
+	} else {
+		mg.webhookVerifier.Rotate(resp.SigningKey)
 	}
-	if len(calculatedSignature) != len(signature) {
-		return false, nil
+	for _, v := range verifiers {
+		v.Rotate(resp.SigningKey)
 	}
+	return resp.SigningKey, nil
+}
 
-	return subtle.ConstantTimeCompare(signature, calculatedSignature) == 1, nil
+func generateWebhookSigningKeyApiUrl(m Mailgun) string {
+	return fmt.Sprintf("%s/v5/%s/%s", m.APIBase(), accountsEndpoint, signingKeyEndpoint)
 }
@@ -0,0 +1,194 @@
+// Package webhooks receives the webhooks sent by Mailgun.
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// DefaultMaxBodySize is the maximum size of the request bodies accepted by default.
+const DefaultMaxBodySize = 1 << 20
+
+// PermanentError is an error that Mailgun must not retry, see Permanent.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent marks the error returned by an event handler as permanent: the Handler responds with
+// 406 Not Acceptable, so Mailgun does not retry the webhook. Other errors are retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// HandlerOptions modifies the behavior of the Handler.
+type HandlerOptions struct {
+	// MaxBodySize is the maximum size of the request body. Defaults to DefaultMaxBodySize.
+	MaxBodySize int64
+	// OnError is called with the error of every failed request, e.g. for logging.
+	OnError func(r *http.Request, err error)
+	// Legacy also accepts the legacy form-encoded webhooks, see events.ParseLegacyEvent,
+	// so the domains can be migrated to the JSON webhooks one at a time.
+	Legacy bool
+}
+
+// Handler is an http.Handler receiving event webhooks. It verifies the signature of the payload,
+// parses its event and dispatches it.
+//
+// It responds with 200 OK once the event is handled, and with 406 Not Acceptable when the request
+// is invalid or the handler returned a permanent error, so Mailgun does not retry it. Other handler
+// errors respond with 500 Internal Server Error, and Mailgun retries the webhook later: the token of
+// the request is then forgotten, so the retry is not rejected as replayed.
+//
+//	d := events.NewDispatcher()
+//	events.Handle(d, func(ctx context.Context, e *events.Delivered) error {
+//		fmt.Printf("delivered to %s\n", e.Recipient)
+//		return nil
+//	})
+//	http.Handle("/webhooks", webhooks.NewHandler(webhooks.NewVerifier("WEBHOOK_SIGNING_KEY"), d, nil))
+type Handler struct {
+	verifier   *Verifier
+	dispatcher *events.Dispatcher
+	opts       HandlerOptions
+}
+
+// NewHandler creates a handler verifying the signatures with the verifier and dispatching the events
+// to the dispatcher.
+func NewHandler(verifier *Verifier, dispatcher *events.Dispatcher, opts *HandlerOptions) *Handler {
+	h := Handler{
+		verifier:   verifier,
+		dispatcher: dispatcher,
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.MaxBodySize <= 0 {
+		h.opts.MaxBodySize = DefaultMaxBodySize
+	}
+	return &h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		Fail(w, r, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method), h.opts.OnError)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.opts.MaxBodySize)
+	var sig mtypes.Signature
+	var parse func() (events.Event, error)
+	var err error
+	if h.opts.Legacy && isForm(r) {
+		sig, parse, err = h.decodeLegacy(r)
+	} else {
+		sig, parse, err = h.decode(r)
+	}
+	if err != nil {
+		Fail(w, r, http.StatusNotAcceptable, err, h.opts.OnError)
+		return
+	}
+
+	// The event is parsed only once its payload is verified.
+	if err := h.verifier.VerifyContext(r.Context(), sig); err != nil {
+		Fail(w, r, StatusCode(err), err, h.opts.OnError)
+		return
+	}
+	e, err := parse()
+	if err != nil {
+		// Mailgun must not retry an event that cannot be parsed, so the token is not forgotten.
+		Fail(w, r, http.StatusNotAcceptable, err, h.opts.OnError)
+		return
+	}
+
+	if err := h.dispatcher.Dispatch(r.Context(), e); err != nil {
+		status := StatusCode(err)
+		Fail(w, r, status, h.verifier.ForgetRetried(r.Context(), sig, status,
+			fmt.Errorf("while handling event '%s': %w", e.GetID(), err)), h.opts.OnError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// decode returns the signature of the request, and a function parsing its event.
+func (h *Handler) decode(r *http.Request) (mtypes.Signature, func() (events.Event, error), error) {
+	var payload mtypes.WebhookPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return payload.Signature, nil, fmt.Errorf("while decoding webhook payload: %w", err)
+	}
+	return payload.Signature, func() (events.Event, error) {
+		return events.ParseEvent(payload.EventData)
+	}, nil
+}
+
+// decodeLegacy is decode for the legacy form-encoded webhooks.
+func (h *Handler) decodeLegacy(r *http.Request) (mtypes.Signature, func() (events.Event, error), error) {
+	// Legacy bounce and drop webhooks are multipart, with the original message attached.
+	err := r.ParseMultipartForm(h.opts.MaxBodySize)
+	if errors.Is(err, http.ErrNotMultipart) {
+		err = r.ParseForm()
+	}
+	if r.MultipartForm != nil {
+		_ = r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		return mtypes.Signature{}, nil, fmt.Errorf("while parsing legacy webhook form: %w", err)
+	}
+
+	sig := mtypes.Signature{
+		TimeStamp: r.PostForm.Get("timestamp"),
+		Token:     r.PostForm.Get("token"),
+		Signature: r.PostForm.Get("signature"),
+	}
+	return sig, func() (events.Event, error) {
+		return events.ParseLegacyEvent(r.PostForm)
+	}, nil
+}
+
+func isForm(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
+// StatusCode returns the status code of the response to a webhook request failing with the error:
+// 406 Not Acceptable for the errors of the Verifier and the permanent errors, so Mailgun does not
+// retry the request, 503 Service Unavailable when the request was cancelled or the token store failed,
+// and 500 Internal Server Error otherwise. It is used by the handlers of this package, and helps to
+// respond to Mailgun the same way in other handlers.
+func StatusCode(err error) int {
+	var permanent *PermanentError
+	switch {
+	case errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrExpiredTimestamp), errors.Is(err, ErrReplayedToken),
+		errors.As(err, &permanent):
+		return http.StatusNotAcceptable
+	case errors.Is(err, errTokenStore), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Fail calls onError with the error, if not nil, and responds to the failed webhook request with the status code.
+func Fail(w http.ResponseWriter, r *http.Request, status int, err error, onError func(r *http.Request, err error)) {
+	if onError != nil {
+		onError(r, err)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
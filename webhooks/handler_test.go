@@ -0,0 +1,240 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWebhookRequest(t *testing.T, sig mtypes.Signature, eventData string) *http.Request {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{
+		"signature":  sig,
+		"event-data": json.RawMessage(eventData),
+	})
+	require.NoError(t, err)
+	return httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body))
+}
+
+var tokenCount atomic.Int64
+
+func newSignature(key string) mtypes.Signature {
+	return Sign(key, strconv.FormatInt(time.Now().Unix(), 10), "token-"+strconv.FormatInt(tokenCount.Add(1), 10))
+}
+
+func TestHandler(t *testing.T) {
+	var delivered []string
+	var handlerErr error
+	d := events.NewDispatcher()
+	events.Handle(d, func(_ context.Context, e *events.Delivered) error {
+		delivered = append(delivered, e.Recipient)
+		return handlerErr
+	})
+
+	var errs []error
+	h := NewHandler(NewVerifier(testSigningKey), d, &HandlerOptions{
+		MaxBodySize: 1024,
+		OnError: func(_ *http.Request, err error) {
+			errs = append(errs, err)
+		},
+	})
+	serve := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	deliveredEvent := `{"event": "delivered", "id": "1", "timestamp": 1533922516.5, "recipient": "joe@example.com"}`
+
+	assert.Equal(t, http.StatusOK, serve(newWebhookRequest(t, newSignature(testSigningKey), deliveredEvent)))
+	assert.Equal(t, []string{"joe@example.com"}, delivered)
+	assert.Empty(t, errs)
+
+	// Events without a handler are accepted.
+	assert.Equal(t, http.StatusOK, serve(newWebhookRequest(t, newSignature(testSigningKey), `{"event": "opened", "id": "2"}`)))
+	assert.Equal(t, http.StatusOK, serve(newWebhookRequest(t, newSignature(testSigningKey), `{"event": "new-event", "id": "3"}`)))
+
+	// Invalid requests are not retried.
+	assert.Equal(t, http.StatusNotAcceptable, serve(newWebhookRequest(t, newSignature("other key"), deliveredEvent)))
+	assert.ErrorIs(t, errs[len(errs)-1], ErrInvalidSignature)
+	assert.Equal(t, http.StatusNotAcceptable, serve(httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{"))))
+	assert.Equal(t, http.StatusNotAcceptable, serve(newWebhookRequest(t, newSignature(testSigningKey), `{"event": "delivered", "timestamp": "now"}`)))
+	large := `{"event": "delivered", "id": "` + strings.Repeat("x", 2048) + `"}`
+	assert.Equal(t, http.StatusNotAcceptable, serve(newWebhookRequest(t, newSignature(testSigningKey), large)))
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(httptest.NewRequest(http.MethodGet, "/", nil)))
+
+	// Handler errors are retried, unless permanent.
+	handlerErr = errors.New("database is down")
+	assert.Equal(t, http.StatusInternalServerError, serve(newWebhookRequest(t, newSignature(testSigningKey), deliveredEvent)))
+	assert.ErrorIs(t, errs[len(errs)-1], handlerErr)
+	handlerErr = Permanent(errors.New("unknown recipient"))
+	assert.Equal(t, http.StatusNotAcceptable, serve(newWebhookRequest(t, newSignature(testSigningKey), deliveredEvent)))
+	handlerErr = context.Canceled
+	assert.Equal(t, http.StatusServiceUnavailable, serve(newWebhookRequest(t, newSignature(testSigningKey), deliveredEvent)))
+}
+
+func TestHandlerExpiredTimestamp(t *testing.T) {
+	h := NewHandler(NewVerifier(testSigningKey), events.NewDispatcher(), nil)
+
+	sig := Sign(testSigningKey, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10), "token")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newWebhookRequest(t, sig, `{"event": "delivered"}`))
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+}
+
+func TestHandlerVerifiesBeforeParsing(t *testing.T) {
+	var errs []error
+	h := NewHandler(NewVerifier(testSigningKey), events.NewDispatcher(), &HandlerOptions{
+		OnError: func(_ *http.Request, err error) { errs = append(errs, err) },
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newWebhookRequest(t, newSignature("other key"), `{"event": 42}`))
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrInvalidSignature)
+}
+
+func TestHandlerReplayedToken(t *testing.T) {
+	var calls int
+	d := events.NewDispatcher()
+	d.HandleAny(func(context.Context, events.Event) error {
+		calls++
+		return nil
+	})
+	h := NewHandler(NewVerifier(testSigningKey), d, nil)
+
+	sig := newSignature(testSigningKey)
+	for _, status := range []int{http.StatusOK, http.StatusNotAcceptable} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newWebhookRequest(t, sig, `{"event": "delivered"}`))
+		assert.Equal(t, status, w.Code)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestHandlerRetryAfterError(t *testing.T) {
+	var handlerErr error
+	var calls int
+	d := events.NewDispatcher()
+	d.HandleAny(func(context.Context, events.Event) error {
+		calls++
+		return handlerErr
+	})
+	h := NewHandler(NewVerifier(testSigningKey), d, nil)
+	serve := func(sig mtypes.Signature) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newWebhookRequest(t, sig, `{"event": "delivered"}`))
+		return w.Code
+	}
+
+	// The retry of a failed request is handled, then the token is used.
+	sig := newSignature(testSigningKey)
+	handlerErr = errors.New("database is down")
+	assert.Equal(t, http.StatusInternalServerError, serve(sig))
+	handlerErr = nil
+	assert.Equal(t, http.StatusOK, serve(sig))
+	assert.Equal(t, http.StatusNotAcceptable, serve(sig))
+	assert.Equal(t, 2, calls)
+
+	// Permanent errors are not retried: the token is kept.
+	sig = newSignature(testSigningKey)
+	handlerErr = Permanent(errors.New("unknown recipient"))
+	assert.Equal(t, http.StatusNotAcceptable, serve(sig))
+	handlerErr = nil
+	assert.Equal(t, http.StatusNotAcceptable, serve(sig))
+	assert.Equal(t, 3, calls)
+}
+
+type failingTokenStore struct{}
+
+func (failingTokenStore) Add(context.Context, string, time.Time) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func (failingTokenStore) Remove(context.Context, string) error {
+	return errors.New("connection refused")
+}
+
+func TestHandlerTokenStoreError(t *testing.T) {
+	v := NewVerifier(testSigningKey)
+	v.Tokens = failingTokenStore{}
+	h := NewHandler(v, events.NewDispatcher(), nil)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newWebhookRequest(t, newSignature(testSigningKey), `{"event": "delivered"}`))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestHandlerLegacy(t *testing.T) {
+	var failed []string
+	d := events.NewDispatcher()
+	events.Handle(d, func(_ context.Context, e *events.Failed) error {
+		failed = append(failed, e.Recipient)
+		return nil
+	})
+	newLegacyRequest := func(sig mtypes.Signature) *http.Request {
+		form := url.Values{
+			"event":     {"bounced"},
+			"recipient": {"alice@example.com"},
+			"code":      {"550"},
+			"timestamp": {sig.TimeStamp},
+			"token":     {sig.Token},
+			"signature": {sig.Signature},
+		}
+		r := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+	serve := func(h *Handler, r *http.Request) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Legacy webhooks are rejected unless enabled.
+	h := NewHandler(NewVerifier(testSigningKey), d, nil)
+	assert.Equal(t, http.StatusNotAcceptable, serve(h, newLegacyRequest(newSignature(testSigningKey))))
+
+	h = NewHandler(NewVerifier(testSigningKey), d, &HandlerOptions{Legacy: true})
+	assert.Equal(t, http.StatusOK, serve(h, newLegacyRequest(newSignature(testSigningKey))))
+	assert.Equal(t, []string{"alice@example.com"}, failed)
+	assert.Equal(t, http.StatusNotAcceptable, serve(h, newLegacyRequest(newSignature("other key"))))
+	assert.Len(t, failed, 1)
+
+	// JSON webhooks are still accepted.
+	deliveredEvent := `{"event": "delivered", "id": "1", "timestamp": 1533922516.5}`
+	assert.Equal(t, http.StatusOK, serve(h, newWebhookRequest(t, newSignature(testSigningKey), deliveredEvent)))
+}
+
+func TestStatusCode(t *testing.T) {
+	for _, tt := range []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("wrapped: %w", ErrInvalidSignature), http.StatusNotAcceptable},
+		{ErrExpiredTimestamp, http.StatusNotAcceptable},
+		{ErrReplayedToken, http.StatusNotAcceptable},
+		{Permanent(errors.New("unknown recipient")), http.StatusNotAcceptable},
+		{fmt.Errorf("%w: %w", errTokenStore, errors.New("connection refused")), http.StatusServiceUnavailable},
+		{context.Canceled, http.StatusServiceUnavailable},
+		{errors.New("database is down"), http.StatusInternalServerError},
+	} {
+		assert.Equal(t, tt.status, StatusCode(tt.err), tt.err.Error())
+	}
+}
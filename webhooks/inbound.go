@@ -0,0 +1,296 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/internal/msgid"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+const (
+	// DefaultMaxInboundBodySize is the maximum size of the inbound message requests accepted by default.
+	// Mailgun accepts messages up to 25 MB, which grow by a third once encoded.
+	DefaultMaxInboundBodySize = 64 << 20
+	// DefaultMaxInboundMemory is the part of an inbound message request kept in memory by default,
+	// the rest of the attachments is stored in temporary files.
+	DefaultMaxInboundMemory = 8 << 20
+)
+
+// HeaderField is a header of a message.
+type HeaderField struct {
+	Name  string
+	Value string
+}
+
+// Headers are the headers of a message, in order and including repeated headers.
+type Headers []HeaderField
+
+// Get returns the value of the first header with the name, case-insensitively, or "" if there is none.
+func (h Headers) Get(name string) string {
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Values returns the values of every header with the name, case-insensitively.
+func (h Headers) Values(name string) []string {
+	var values []string
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			values = append(values, f.Value)
+		}
+	}
+	return values
+}
+
+// InboundAttachment is an attachment of an inbound message. Its content is streamed from
+// the request, in memory or from a temporary file for large attachments.
+type InboundAttachment struct {
+	// Field is the form field of the attachment, e.g. "attachment-1".
+	Field       string
+	Filename    string
+	ContentType string
+	Size        int64
+
+	file *multipart.FileHeader
+}
+
+// Open opens the content of the attachment. The caller must close it.
+func (a *InboundAttachment) Open() (io.ReadCloser, error) {
+	if a.file == nil {
+		return nil, fmt.Errorf("attachment '%s' has no content", a.Field)
+	}
+	return a.file.Open()
+}
+
+// InboundMessage is a message forwarded to a URL by a route, see
+// https://documentation.mailgun.com/docs/mailgun/user-manual/receive-forward-store/receive-http
+type InboundMessage struct {
+	Recipient         string // recipient
+	Sender            string // sender
+	From              string // from
+	Subject           string // subject
+	BodyPlain         string // body-plain
+	BodyHTML          string // body-html
+	StrippedText      string // stripped-text
+	StrippedHTML      string // stripped-html
+	StrippedSignature string // stripped-signature
+	// BodyMIME is the raw message, set instead of the bodies when the route URL ends with "mime".
+	BodyMIME string // body-mime
+	// Headers are the headers of the message, in order.
+	Headers Headers // message-headers
+	// ContentIDMap maps the Content-ID of the inline attachments to their form field, e.g. "attachment-1".
+	ContentIDMap map[string]string // content-id-map
+	Attachments  []*InboundAttachment
+	Signature    mtypes.Signature
+	Timestamp    time.Time
+}
+
+// MessageID returns the Message-Id header, without the angle brackets.
+func (m *InboundMessage) MessageID() string {
+	return msgid.Trim(m.Headers.Get("Message-Id"))
+}
+
+// InReplyTo returns the In-Reply-To header, without the angle brackets.
+func (m *InboundMessage) InReplyTo() string {
+	return msgid.Trim(m.Headers.Get("In-Reply-To"))
+}
+
+// References returns the message IDs of the References header, without the angle brackets.
+func (m *InboundMessage) References() []string {
+	return msgid.Parse(m.Headers.Get("References"))
+}
+
+// Attachment returns the attachment of the form field, e.g. the field of an inline attachment
+// in ContentIDMap, or nil if there is none.
+func (m *InboundMessage) Attachment(field string) *InboundAttachment {
+	for _, a := range m.Attachments {
+		if a.Field == field {
+			return a
+		}
+	}
+	return nil
+}
+
+// ParseInboundMessage parses an inbound message request, multipart or URL-encoded, keeping up to
+// maxMemory bytes of attachments in memory. The rest is stored in temporary files, removed by
+// calling r.MultipartForm.RemoveAll. It does not verify the signature.
+func ParseInboundMessage(r *http.Request, maxMemory int64) (*InboundMessage, error) {
+	err := r.ParseMultipartForm(maxMemory)
+	if errors.Is(err, http.ErrNotMultipart) {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("while parsing inbound message form: %w", err)
+	}
+
+	form := r.PostForm
+	m := InboundMessage{
+		Recipient:         form.Get("recipient"),
+		Sender:            form.Get("sender"),
+		From:              form.Get("from"),
+		Subject:           form.Get("subject"),
+		BodyPlain:         form.Get("body-plain"),
+		BodyHTML:          form.Get("body-html"),
+		StrippedText:      form.Get("stripped-text"),
+		StrippedHTML:      form.Get("stripped-html"),
+		StrippedSignature: form.Get("stripped-signature"),
+		BodyMIME:          form.Get("body-mime"),
+		Signature: mtypes.Signature{
+			TimeStamp: form.Get("timestamp"),
+			Token:     form.Get("token"),
+			Signature: form.Get("signature"),
+		},
+	}
+
+	if ts, err := strconv.ParseInt(m.Signature.TimeStamp, 10, 64); err == nil {
+		m.Timestamp = time.Unix(ts, 0)
+	}
+
+	if s := form.Get("message-headers"); s != "" {
+		var headers [][]string
+		if err := json.Unmarshal([]byte(s), &headers); err != nil {
+			return nil, fmt.Errorf("while decoding message-headers: %w", err)
+		}
+		for _, h := range headers {
+			if len(h) < 2 {
+				continue
+			}
+			m.Headers = append(m.Headers, HeaderField{Name: h[0], Value: h[1]})
+		}
+	}
+
+	if s := form.Get("content-id-map"); s != "" {
+		if err := json.Unmarshal([]byte(s), &m.ContentIDMap); err != nil {
+			return nil, fmt.Errorf("while decoding content-id-map: %w", err)
+		}
+	}
+
+	if r.MultipartForm != nil {
+		m.Attachments = inboundAttachments(r.MultipartForm, form.Get("attachment-count"))
+	}
+	return &m, nil
+}
+
+// inboundAttachments returns the attachments "attachment-1" to "attachment-N" of the form. Without
+// an attachment count, it stops at the first missing attachment.
+func inboundAttachments(form *multipart.Form, count string) []*InboundAttachment {
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		n = -1
+	}
+
+	var attachments []*InboundAttachment
+	for i := 1; n < 0 || i <= n; i++ {
+		field := "attachment-" + strconv.Itoa(i)
+		files := form.File[field]
+		if len(files) == 0 {
+			if n < 0 {
+				break
+			}
+			continue
+		}
+		f := files[0]
+		attachments = append(attachments, &InboundAttachment{
+			Field:       field,
+			Filename:    f.Filename,
+			ContentType: textproto.MIMEHeader(f.Header).Get("Content-Type"),
+			Size:        f.Size,
+			file:        f,
+		})
+	}
+	return attachments
+}
+
+// InboundHandlerFunc handles an inbound message. The attachments can only be read until it returns.
+type InboundHandlerFunc func(ctx context.Context, m *InboundMessage) error
+
+// InboundHandlerOptions modifies the behavior of the InboundHandler.
+type InboundHandlerOptions struct {
+	// MaxBodySize is the maximum size of the request body. Defaults to DefaultMaxInboundBodySize.
+	MaxBodySize int64
+	// MaxMemory is the part of the request kept in memory. Defaults to DefaultMaxInboundMemory.
+	MaxMemory int64
+	// OnError is called with the error of every failed request, e.g. for logging.
+	OnError func(r *http.Request, err error)
+}
+
+// InboundHandler is an http.Handler receiving the messages forwarded to a URL by a route,
+// with their attachments. It verifies the signature of the request, parses the message and
+// passes it to the handler function. It responds as the Handler does.
+//
+//	v := webhooks.NewVerifier("WEBHOOK_SIGNING_KEY")
+//	http.Handle("/inbound", webhooks.NewInboundHandler(v, func(ctx context.Context, m *webhooks.InboundMessage) error {
+//		for _, a := range m.Attachments {
+//			fmt.Printf("%s: %s (%d bytes)\n", m.Subject, a.Filename, a.Size)
+//		}
+//		return nil
+//	}, nil))
+type InboundHandler struct {
+	verifier *Verifier
+	handle   InboundHandlerFunc
+	opts     InboundHandlerOptions
+}
+
+// NewInboundHandler creates a handler verifying the signatures with the verifier and passing
+// the inbound messages to the handler function.
+func NewInboundHandler(verifier *Verifier, handle InboundHandlerFunc, opts *InboundHandlerOptions) *InboundHandler {
+	h := InboundHandler{
+		verifier: verifier,
+		handle:   handle,
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.MaxBodySize <= 0 {
+		h.opts.MaxBodySize = DefaultMaxInboundBodySize
+	}
+	if h.opts.MaxMemory <= 0 {
+		h.opts.MaxMemory = DefaultMaxInboundMemory
+	}
+	return &h
+}
+
+func (h *InboundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		Fail(w, r, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method), h.opts.OnError)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.opts.MaxBodySize)
+	m, err := ParseInboundMessage(r, h.opts.MaxMemory)
+	if r.MultipartForm != nil {
+		defer func() { _ = r.MultipartForm.RemoveAll() }()
+	}
+	if err != nil {
+		Fail(w, r, http.StatusNotAcceptable, err, h.opts.OnError)
+		return
+	}
+
+	if err := h.verifier.VerifyContext(r.Context(), m.Signature); err != nil {
+		Fail(w, r, StatusCode(err), err, h.opts.OnError)
+		return
+	}
+
+	if err := h.handle(r.Context(), m); err != nil {
+		status := StatusCode(err)
+		Fail(w, r, status, h.verifier.ForgetRetried(r.Context(), m.Signature, status,
+			fmt.Errorf("while handling inbound message '%s': %w", m.MessageID(), err)), h.opts.OnError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,178 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inboundHeaders = `[
+	["Received", "from mx1.example.com"],
+	["Received", "from mx2.example.com"],
+	["Message-Id", "<reply@example.com>"],
+	["In-Reply-To", "<original@example.com>"],
+	["References", "<first@example.com> <original@example.com>"]
+]`
+
+type inboundFile struct {
+	field, filename, contentType, content string
+}
+
+func newInboundRequest(t *testing.T, sig mtypes.Signature, fields map[string]string, files ...inboundFile) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("timestamp", sig.TimeStamp))
+	require.NoError(t, mw.WriteField("token", sig.Token))
+	require.NoError(t, mw.WriteField("signature", sig.Signature))
+	for name, value := range fields {
+		require.NoError(t, mw.WriteField(name, value))
+	}
+	for _, f := range files {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="`+f.field+`"; filename="`+f.filename+`"`)
+		h.Set("Content-Type", f.contentType)
+		w, err := mw.CreatePart(h)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, f.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/inbound", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestParseInboundMessage(t *testing.T) {
+	sig := newSignature(testSigningKey)
+	r := newInboundRequest(t, sig, map[string]string{
+		"recipient":          "support@example.com",
+		"sender":             "joe@example.net",
+		"from":               "Joe <joe@example.net>",
+		"subject":            "Re: Invoice",
+		"body-plain":         "See attached.\n\n> Previous message",
+		"body-html":          "<p>See attached.</p><img src=\"cid:logo\">",
+		"stripped-text":      "See attached.",
+		"stripped-signature": "Joe",
+		"message-headers":    inboundHeaders,
+		"content-id-map":     `{"<logo>": "attachment-2"}`,
+		"attachment-count":   "2",
+	},
+		inboundFile{"attachment-1", "invoice.pdf", "application/pdf", "%PDF-1.4"},
+		inboundFile{"attachment-2", "logo.png", "image/png", "PNG"},
+	)
+
+	m, err := ParseInboundMessage(r, 1)
+	require.NoError(t, err)
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	assert.Equal(t, "support@example.com", m.Recipient)
+	assert.Equal(t, "Joe <joe@example.net>", m.From)
+	assert.Equal(t, "<p>See attached.</p><img src=\"cid:logo\">", m.BodyHTML)
+	assert.Equal(t, "Joe", m.StrippedSignature)
+	assert.Equal(t, sig, m.Signature)
+	assert.Equal(t, sig.TimeStamp, strconv.FormatInt(m.Timestamp.Unix(), 10))
+
+	// Repeated headers are kept in order.
+	assert.Equal(t, []string{"from mx1.example.com", "from mx2.example.com"}, m.Headers.Values("received"))
+	assert.Equal(t, "reply@example.com", m.MessageID())
+	assert.Equal(t, "original@example.com", m.InReplyTo())
+	assert.Equal(t, []string{"first@example.com", "original@example.com"}, m.References())
+
+	require.Len(t, m.Attachments, 2)
+	assert.Equal(t, "invoice.pdf", m.Attachments[0].Filename)
+	assert.Equal(t, "application/pdf", m.Attachments[0].ContentType)
+	assert.Equal(t, int64(8), m.Attachments[0].Size)
+
+	logo := m.Attachment(m.ContentIDMap["<logo>"])
+	require.NotNil(t, logo)
+	f, err := logo.Open()
+	require.NoError(t, err)
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, "PNG", string(content))
+}
+
+func TestParseInboundMessageURLEncoded(t *testing.T) {
+	form := url.Values{
+		"recipient":       {"support@example.com"},
+		"subject":         {"Hello"},
+		"message-headers": {`[["Subject", "Hello"]]`},
+		"timestamp":       {"1533922516"},
+	}
+	r := httptest.NewRequest(http.MethodPost, "/inbound", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	m, err := ParseInboundMessage(r, DefaultMaxInboundMemory)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", m.Headers.Get("subject"))
+	assert.Equal(t, int64(1533922516), m.Timestamp.Unix())
+	assert.Empty(t, m.Attachments)
+
+	r = httptest.NewRequest(http.MethodPost, "/inbound", strings.NewReader("message-headers=%7B"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err = ParseInboundMessage(r, DefaultMaxInboundMemory)
+	assert.Error(t, err)
+}
+
+func TestInboundHandler(t *testing.T) {
+	var subjects []string
+	var handlerErr error
+	var errs []error
+	h := NewInboundHandler(NewVerifier(testSigningKey), func(_ context.Context, m *InboundMessage) error {
+		subjects = append(subjects, m.Subject)
+		for _, a := range m.Attachments {
+			f, err := a.Open()
+			if err != nil {
+				return err
+			}
+			_ = f.Close()
+		}
+		return handlerErr
+	}, &InboundHandlerOptions{
+		MaxBodySize: 4096,
+		OnError: func(_ *http.Request, err error) {
+			errs = append(errs, err)
+		},
+	})
+	serve := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	fields := map[string]string{"subject": "Hello", "attachment-count": "1"}
+	file := inboundFile{"attachment-1", "notes.txt", "text/plain", "notes"}
+
+	assert.Equal(t, http.StatusOK, serve(newInboundRequest(t, newSignature(testSigningKey), fields, file)))
+	assert.Equal(t, []string{"Hello"}, subjects)
+	assert.Empty(t, errs)
+
+	// Invalid requests are not retried.
+	assert.Equal(t, http.StatusNotAcceptable, serve(newInboundRequest(t, newSignature("other key"), fields, file)))
+	assert.ErrorIs(t, errs[len(errs)-1], ErrInvalidSignature)
+	large := inboundFile{"attachment-1", "large.bin", "application/octet-stream", strings.Repeat("x", 8192)}
+	assert.Equal(t, http.StatusNotAcceptable, serve(newInboundRequest(t, newSignature(testSigningKey), fields, large)))
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(httptest.NewRequest(http.MethodGet, "/", nil)))
+	assert.Len(t, subjects, 1)
+
+	// Handler errors are retried, unless permanent.
+	handlerErr = errors.New("storage is down")
+	assert.Equal(t, http.StatusInternalServerError, serve(newInboundRequest(t, newSignature(testSigningKey), fields, file)))
+	handlerErr = Permanent(errors.New("unknown recipient"))
+	assert.Equal(t, http.StatusNotAcceptable, serve(newInboundRequest(t, newSignature(testSigningKey), fields, file)))
+}
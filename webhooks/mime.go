@@ -0,0 +1,111 @@
+package webhooks
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/mailgun/mailgun-go/v5/internal/msgid"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// MIMEPart is a leaf part of a MIME message, see mtypes.MIMEPart.
+type MIMEPart = mtypes.MIMEPart
+
+// MIMEMessage is a parsed MIME message, see mtypes.MIMEMessage.
+type MIMEMessage = mtypes.MIMEMessage
+
+// ParseMIME parses a MIME message, e.g. the body-mime of a stored message, into its headers, bodies and attachments.
+// Nested multiparts are flattened, and attached messages (message/rfc822) are kept as attachments.
+func ParseMIME(r io.Reader) (*MIMEMessage, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("while reading MIME message: %w", err)
+	}
+
+	m := MIMEMessage{Header: msg.Header}
+	if err := addPart(&m, textproto.MIMEHeader(msg.Header), msg.Body); err != nil {
+		return nil, err
+	}
+
+	for _, p := range m.Parts {
+		switch {
+		case p.IsAttachment():
+			m.Attachments = append(m.Attachments, p)
+		case p.ContentType == "text/plain" && m.Text == "":
+			m.Text = string(p.Body)
+		case p.ContentType == "text/html" && m.HTML == "":
+			m.HTML = string(p.Body)
+		}
+	}
+	return &m, nil
+}
+
+// addPart adds the leaf parts of the part to the message.
+func addPart(m *MIMEMessage, header textproto.MIMEHeader, body io.Reader) error {
+	contentType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// RFC 2045: without a valid Content-Type, the part is plain text.
+		contentType, params = "text/plain", map[string]string{"charset": "us-ascii"}
+	}
+
+	if strings.HasPrefix(contentType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("while reading MIME part: %w", err)
+			}
+			if err := addPart(m, part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("while decoding MIME part '%s': %w", contentType, err)
+	}
+
+	p := MIMEPart{
+		Header:      header,
+		ContentType: contentType,
+		Params:      params,
+		ContentID:   msgid.Trim(header.Get("Content-Id")),
+		Body:        content,
+	}
+	if disposition, dparams, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		p.Disposition = disposition
+		p.Filename = dparams["filename"]
+	}
+	if p.Filename == "" {
+		p.Filename = params["name"]
+	}
+	if name, err := new(mime.WordDecoder).DecodeHeader(p.Filename); err == nil {
+		p.Filename = name
+	}
+	m.Parts = append(m.Parts, &p)
+	return nil
+}
+
+// decodeTransferEncoding decodes the Content-Transfer-Encoding of a part. The line breaks of base64 content
+// are ignored by the decoder, and the multipart reader already decodes quoted-printable parts.
+func decodeTransferEncoding(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
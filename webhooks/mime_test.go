@@ -0,0 +1,85 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMIME = "From: Joe <joe@example.net>\r\n" +
+	"To: support@example.com\r\n" +
+	"Subject: Invoice\r\n" +
+	"Received: from mx1.example.com\r\n" +
+	"Received: from mx2.example.com\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/related; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: multipart/alternative; boundary=alt\r\n" +
+	"\r\n" +
+	"--alt\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"See the attached invoice =E2=82=AC\r\n" +
+	"--alt\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>See the attached invoice</p><img src=\"cid:logo@example.net\">\r\n" +
+	"--alt--\r\n" +
+	"--inner\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Id: <logo@example.net>\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBO\r\n" +
+	"Rw==\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=\"=?utf-8?q?facture_=C3=A9t=C3=A9.pdf?=\"\r\n" +
+	"Content-Disposition: attachment\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQ=\r\n" +
+	"--outer--\r\n"
+
+func TestParseMIME(t *testing.T) {
+	m, err := ParseMIME(strings.NewReader(testMIME))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Invoice", m.Header.Get("Subject"))
+	assert.Equal(t, []string{"from mx1.example.com", "from mx2.example.com"}, m.Header["Received"])
+	require.Len(t, m.Parts, 4)
+	assert.Equal(t, "See the attached invoice €", strings.TrimSpace(m.Text))
+	assert.Contains(t, m.HTML, `<img src="cid:logo@example.net">`)
+
+	require.Len(t, m.Attachments, 2)
+	logo := m.Attachments[0]
+	assert.Equal(t, "image/png", logo.ContentType)
+	assert.Equal(t, "logo@example.net", logo.ContentID)
+	assert.Equal(t, "\x89PNG", string(logo.Body))
+	pdf := m.Attachments[1]
+	assert.Equal(t, "attachment", pdf.Disposition)
+	assert.Equal(t, "facture été.pdf", pdf.Filename)
+	assert.Equal(t, "%PDF-1.4", string(pdf.Body))
+
+	assert.Same(t, logo, m.ContentID("cid:logo@example.net"))
+	assert.Nil(t, m.ContentID("missing@example.net"))
+	assert.Equal(t, map[string]*MIMEPart{"<logo@example.net>": logo},
+		m.ResolveContentIDs(map[string]string{"<logo@example.net>": "attachment-1", "<missing>": "attachment-2"}))
+}
+
+func TestParseMIMESinglePart(t *testing.T) {
+	m, err := ParseMIME(strings.NewReader("Subject: Hello\r\nContent-Transfer-Encoding: base64\r\n\r\nSGVsbG8=\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", m.Text)
+	assert.Empty(t, m.Attachments)
+
+	_, err = ParseMIME(strings.NewReader("Content-Type: multipart/mixed; boundary=b\r\n\r\n--b\r\nbroken"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,265 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+)
+
+const (
+	sampleDomain    = "example.com"
+	sampleSender    = "sender@example.com"
+	sampleRecipient = "recipient@example.net"
+)
+
+// SampleEvent returns a realistic event of the registered event type, e.g. events.EventDelivered,
+// timestamped now. Events registered with events.RegisterEvent only have their common fields set.
+func SampleEvent(name string) (events.Event, error) {
+	name = strings.ToLower(name)
+	if !events.IsRegisteredEvent(name) {
+		return nil, fmt.Errorf("event '%s' is not registered", name)
+	}
+
+	var e events.Event
+	switch name {
+	case events.EventAccepted:
+		e = &events.Accepted{
+			Envelope:        sampleEnvelope(),
+			Message:         sampleMessage(),
+			Flags:           events.Flags{IsAuthenticated: true},
+			Recipient:       sampleRecipient,
+			RecipientDomain: domainOf(sampleRecipient),
+			Method:          events.MethodHTTP,
+			OriginatingIP:   "203.0.113.10",
+			Tags:            []string{"sample"},
+			UserVariables:   map[string]any{},
+			Storage:         sampleStorage(),
+		}
+	case events.EventRejected:
+		e = &events.Rejected{
+			Reject: events.Reject{
+				Reason:      "Sandbox subdomains are for test purposes only.",
+				Description: "Please add the recipient to the authorized recipients.",
+			},
+			Message:       sampleMessage(),
+			Storage:       sampleStorage(),
+			Flags:         events.Flags{IsAuthenticated: true},
+			Tags:          []string{"sample"},
+			UserVariables: map[string]any{},
+		}
+	case events.EventDelivered:
+		e = &events.Delivered{
+			Envelope:          sampleEnvelope(),
+			Message:           sampleMessage(),
+			Flags:             events.Flags{IsAuthenticated: true},
+			Recipient:         sampleRecipient,
+			RecipientDomain:   domainOf(sampleRecipient),
+			RecipientProvider: "Other",
+			Method:            events.MethodHTTP,
+			Tags:              []string{"sample"},
+			Storage:           sampleStorage(),
+			PrimaryDkim:       sampleDomain,
+			DeliveryStatus: events.DeliveryStatus{
+				Code:           250,
+				AttemptNo:      1,
+				Message:        "OK",
+				SessionSeconds: 0.9,
+				EnhancedCode:   "2.0.0",
+				MxHost:         "mx.example.net",
+			},
+			UserVariables: map[string]any{},
+		}
+	case events.EventFailed:
+		e = &events.Failed{
+			Envelope:        sampleEnvelope(),
+			Message:         sampleMessage(),
+			Flags:           events.Flags{IsAuthenticated: true},
+			Recipient:       sampleRecipient,
+			RecipientDomain: domainOf(sampleRecipient),
+			Method:          events.MethodHTTP,
+			Tags:            []string{"sample"},
+			Storage:         sampleStorage(),
+			DeliveryStatus: events.DeliveryStatus{
+				Code:           550,
+				AttemptNo:      1,
+				Message:        "5.1.1 The email account that you tried to reach does not exist.",
+				SessionSeconds: 0.4,
+				EnhancedCode:   "5.1.1",
+				MxHost:         "mx.example.net",
+				BounceType:     events.BounceTypeHard,
+			},
+			Severity:      events.SeverityPermanent,
+			Reason:        events.ReasonBounce,
+			UserVariables: map[string]any{},
+		}
+	case events.EventDropped:
+		e = &events.Dropped{
+			Envelope:        sampleEnvelope(),
+			Message:         sampleMessage(),
+			Flags:           events.Flags{IsAuthenticated: true},
+			Recipient:       sampleRecipient,
+			RecipientDomain: domainOf(sampleRecipient),
+			Method:          events.MethodHTTP,
+			Tags:            []string{"sample"},
+			Storage:         sampleStorage(),
+			DeliveryStatus: events.DeliveryStatus{
+				Code:        605,
+				AttemptNo:   1,
+				Description: "Not delivering to previously bounced address",
+				BounceType:  events.BounceTypeHard,
+			},
+			Severity:      events.SeverityPermanent,
+			Reason:        events.ReasonSuppressBounce,
+			Description:   "Not delivering to previously bounced address",
+			UserVariables: map[string]any{},
+		}
+	case events.EventStored:
+		e = &events.Stored{
+			Message:       sampleMessage(),
+			Storage:       sampleStorage(),
+			Flags:         events.Flags{IsRouted: true},
+			UserVariables: map[string]any{},
+		}
+	case events.EventOpened:
+		e = &events.Opened{
+			Message:         sampleMessage(),
+			Recipient:       sampleRecipient,
+			RecipientDomain: domainOf(sampleRecipient),
+			Tags:            []string{"sample"},
+			IP:              "198.51.100.20",
+			ClientInfo:      sampleClientInfo(),
+			GeoLocation:     sampleGeoLocation(),
+			UserVariables:   map[string]any{},
+		}
+	case events.EventClicked:
+		e = &events.Clicked{
+			Url:             "https://example.com/welcome",
+			Message:         sampleMessage(),
+			Recipient:       sampleRecipient,
+			RecipientDomain: domainOf(sampleRecipient),
+			Tags:            []string{"sample"},
+			IP:              "198.51.100.20",
+			ClientInfo:      sampleClientInfo(),
+			GeoLocation:     sampleGeoLocation(),
+			UserVariables:   map[string]any{},
+		}
+	case events.EventUnsubscribed:
+		e = &events.Unsubscribed{
+			Message:         sampleMessage(),
+			Recipient:       sampleRecipient,
+			RecipientDomain: domainOf(sampleRecipient),
+			Tags:            []string{"sample"},
+			IP:              "198.51.100.20",
+			ClientInfo:      sampleClientInfo(),
+			GeoLocation:     sampleGeoLocation(),
+			UserVariables:   map[string]any{},
+		}
+	case events.EventComplained:
+		e = &events.Complained{
+			Message:       sampleMessage(),
+			Recipient:     sampleRecipient,
+			Tags:          []string{"sample"},
+			UserVariables: map[string]any{},
+		}
+	case events.EventListMemberUploaded:
+		e = &events.ListMemberUploaded{
+			MailingList: sampleMailingList(),
+			Member:      events.MailingListMember{Subscribed: true, Address: sampleRecipient, Name: "Recipient"},
+			TaskID:      randomID(),
+		}
+	case events.EventListMemberUploadError:
+		e = &events.ListMemberUploadError{
+			MailingList:       sampleMailingList(),
+			TaskID:            randomID(),
+			Format:            "csv",
+			MemberDescription: "not-an-address,Nobody",
+			Error:             events.MailingListError{Message: "invalid address"},
+		}
+	case events.EventListUploaded:
+		e = &events.ListUploaded{
+			MailingList:   sampleMailingList(),
+			IsUpsert:      true,
+			Format:        "csv",
+			UpsertedCount: 1,
+			Member:        events.MailingListMember{Subscribed: true, Address: sampleRecipient, Name: "Recipient"},
+			Subscribed:    true,
+			TaskID:        randomID(),
+		}
+	default:
+		var ok bool
+		if e, ok = events.NewEvent(name); !ok {
+			return nil, fmt.Errorf("event '%s' is not registered", name)
+		}
+	}
+
+	e.SetName(name)
+	e.SetID(randomID())
+	e.SetTimestamp(time.Now())
+	return e, nil
+}
+
+func sampleEnvelope() events.Envelope {
+	return events.Envelope{
+		MailFrom:    "bounce+" + randomID() + "@" + sampleDomain,
+		Sender:      sampleSender,
+		Transport:   events.TransportSMTP,
+		Targets:     sampleRecipient,
+		SendingHost: "smtp-out.example.com",
+		SendingIP:   "192.0.2.25",
+	}
+}
+
+func sampleMessage() events.Message {
+	return events.Message{
+		Headers: events.MessageHeaders{
+			To:        sampleRecipient,
+			MessageID: randomID() + "@" + sampleDomain,
+			From:      "Sender <" + sampleSender + ">",
+			Subject:   "Sample message",
+		},
+		Attachments: []events.Attachment{},
+		Recipients:  []string{sampleRecipient},
+		Size:        1024,
+	}
+}
+
+func sampleStorage() events.Storage {
+	key := randomID()
+	return events.Storage{
+		Key: key,
+		URL: "https://storage.mailgun.net/v3/domains/" + sampleDomain + "/messages/" + key,
+	}
+}
+
+func sampleClientInfo() events.ClientInfo {
+	return events.ClientInfo{
+		ClientName: "Firefox",
+		ClientOS:   "Linux",
+		ClientType: events.ClientBrowser,
+		DeviceType: "desktop",
+		UserAgent:  "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0",
+	}
+}
+
+func sampleGeoLocation() events.GeoLocation {
+	return events.GeoLocation{City: "San Antonio", Country: "US", Region: "TX"}
+}
+
+func sampleMailingList() events.MailingList {
+	return events.MailingList{Address: "list@" + sampleDomain, ListID: "list", SID: randomID()}
+}
+
+func domainOf(address string) string {
+	_, domain, _ := strings.Cut(address, "@")
+	return domain
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
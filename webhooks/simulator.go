@@ -0,0 +1,230 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// MailgunRetrySchedule is the delays between the retries of a failed webhook by Mailgun.
+// Scale it down to simulate the retries in tests.
+var MailgunRetrySchedule = []time.Duration{
+	10 * time.Minute,
+	10 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	4 * time.Hour,
+}
+
+// Fault is a failure injected by the Simulator in a webhook request, to check that the endpoint rejects it.
+type Fault string
+
+const (
+	// FaultNone sends a valid request.
+	FaultNone Fault = ""
+	// FaultBadSignature signs the request with another key.
+	FaultBadSignature Fault = "bad-signature"
+	// FaultExpiredTimestamp signs the request with a timestamp one hour ago.
+	FaultExpiredTimestamp Fault = "expired-timestamp"
+	// FaultReplay sends a valid request, then the same request again. The first status code
+	// is recorded as the first attempt of the delivery.
+	FaultReplay Fault = "replay"
+	// FaultMalformed sends a truncated JSON body.
+	FaultMalformed Fault = "malformed"
+)
+
+// ParseFault returns the fault of the name, e.g. "bad-signature". The empty name is FaultNone.
+func ParseFault(name string) (Fault, error) {
+	switch f := Fault(name); f {
+	case FaultNone, FaultBadSignature, FaultExpiredTimestamp, FaultReplay, FaultMalformed:
+		return f, nil
+	default:
+		return FaultNone, fmt.Errorf("unknown fault '%s'", name)
+	}
+}
+
+// SimulatorOptions modifies the behavior of the Simulator.
+type SimulatorOptions struct {
+	// HTTPClient sends the requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// RetrySchedule is the delays between the retries of a failed webhook, e.g. a scaled down
+	// MailgunRetrySchedule. As Mailgun does, webhooks are retried unless the endpoint responds with
+	// 200 OK or 406 Not Acceptable. Defaults to no retries.
+	RetrySchedule []time.Duration
+	// Faults is the failure schedule: the fault of the n-th webhook sent is Faults[n % len(Faults)].
+	// Defaults to no faults.
+	Faults []Fault
+}
+
+// Simulator signs and posts webhooks to an endpoint, as Mailgun does, to exercise webhook consumers in tests.
+//
+//	sim := webhooks.NewSimulator("WEBHOOK_SIGNING_KEY", nil)
+//	deliveries, err := sim.SendAll(ctx, "http://localhost:8080/webhooks")
+type Simulator struct {
+	signingKey string
+	opts       SimulatorOptions
+	sent       int
+}
+
+// Delivery is the result of a webhook sent by the Simulator.
+type Delivery struct {
+	Event events.Event
+	Fault Fault
+	// Attempts are the status codes of the responses to each attempt, zero when the request failed.
+	Attempts []int
+	// Err is the error of the last attempt, if any.
+	Err error
+}
+
+// Status returns the status code of the last attempt.
+func (d *Delivery) Status() int {
+	if len(d.Attempts) == 0 {
+		return 0
+	}
+	return d.Attempts[len(d.Attempts)-1]
+}
+
+// NewSimulator creates a simulator signing the webhooks with the signing key.
+// A Simulator is not safe for concurrent use.
+func NewSimulator(signingKey string, opts *SimulatorOptions) *Simulator {
+	s := Simulator{signingKey: signingKey}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.HTTPClient == nil {
+		s.opts.HTTPClient = http.DefaultClient
+	}
+	return &s
+}
+
+// Payload returns the JSON webhook payload of the event, signed now with a new token.
+func (s *Simulator) Payload(e events.Event) ([]byte, error) {
+	return s.payload(e, s.signingKey, time.Now())
+}
+
+func (s *Simulator) payload(e events.Event, signingKey string, at time.Time) ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("while encoding event '%s': %w", e.GetName(), err)
+	}
+	return json.Marshal(mtypes.WebhookPayload{
+		Signature: Sign(signingKey, strconv.FormatInt(at.Unix(), 10), randomID()),
+		EventData: events.RawJSON(data),
+	})
+}
+
+// SendAll sends a sample event of each name, see SampleEvent, or of every registered event
+// if no name is given. It stops when the context is cancelled, returning the deliveries already made.
+func (s *Simulator) SendAll(ctx context.Context, url string, names ...string) ([]Delivery, error) {
+	if len(names) == 0 {
+		names = events.RegisteredEvents()
+	}
+
+	var deliveries []Delivery
+	for _, name := range names {
+		e, err := SampleEvent(name)
+		if err != nil {
+			return deliveries, err
+		}
+		d := s.Send(ctx, url, e)
+		deliveries = append(deliveries, d)
+		if ctx.Err() != nil {
+			return deliveries, ctx.Err()
+		}
+	}
+	return deliveries, nil
+}
+
+// Send posts the event to the URL, with the next fault of the failure schedule, and retries
+// following the retry schedule until the endpoint responds with 200 OK or 406 Not Acceptable.
+// Each retry is signed again with a new token.
+func (s *Simulator) Send(ctx context.Context, url string, e events.Event) Delivery {
+	d := Delivery{Event: e}
+	if len(s.opts.Faults) > 0 {
+		d.Fault = s.opts.Faults[s.sent%len(s.opts.Faults)]
+	}
+	s.sent++
+
+	for attempt := 0; ; attempt++ {
+		body, err := s.faultyPayload(e, d.Fault)
+		if err != nil {
+			d.Err = err
+			return d
+		}
+
+		if d.Fault == FaultReplay && attempt == 0 {
+			status, err := s.post(ctx, url, body)
+			d.Attempts = append(d.Attempts, status)
+			if err != nil {
+				d.Err = err
+				return d
+			}
+		}
+
+		status, err := s.post(ctx, url, body)
+		d.Attempts = append(d.Attempts, status)
+		d.Err = err
+		if err == nil && (status == http.StatusOK || status == http.StatusNotAcceptable) {
+			return d
+		}
+		if err == nil {
+			d.Err = fmt.Errorf("webhook endpoint responded with status %d", status)
+		}
+		if attempt >= len(s.opts.RetrySchedule) {
+			return d
+		}
+
+		timer := time.NewTimer(s.opts.RetrySchedule[attempt])
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.Err = ctx.Err()
+			return d
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Simulator) faultyPayload(e events.Event, fault Fault) ([]byte, error) {
+	switch fault {
+	case FaultBadSignature:
+		return s.payload(e, "not-"+s.signingKey, time.Now())
+	case FaultExpiredTimestamp:
+		return s.payload(e, s.signingKey, time.Now().Add(-time.Hour))
+	case FaultMalformed:
+		body, err := s.payload(e, s.signingKey, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return body[:len(body)/2], nil
+	default:
+		return s.payload(e, s.signingKey, time.Now())
+	}
+}
+
+func (s *Simulator) post(ctx context.Context, url string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mailgun-go webhook simulator")
+
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
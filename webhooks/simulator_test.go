@@ -0,0 +1,111 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleEvent(t *testing.T) {
+	for _, name := range events.RegisteredEvents() {
+		e, err := SampleEvent(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, e.GetName())
+		assert.NotEmpty(t, e.GetID())
+		assert.WithinDuration(t, time.Now(), e.GetTimestamp(), time.Minute)
+		want, ok := events.NewEvent(name)
+		require.True(t, ok)
+		assert.Equal(t, reflect.TypeOf(want), reflect.TypeOf(e))
+	}
+
+	_, err := SampleEvent("no-such-event")
+	assert.Error(t, err)
+}
+
+func TestSimulator(t *testing.T) {
+	var received []string
+	d := events.NewDispatcher()
+	d.HandleAny(func(_ context.Context, e events.Event) error {
+		received = append(received, e.GetName())
+		return nil
+	})
+	srv := httptest.NewServer(NewHandler(NewVerifier(testSigningKey), d, nil))
+	defer srv.Close()
+
+	sim := NewSimulator(testSigningKey, nil)
+	deliveries, err := sim.SendAll(context.Background(), srv.URL)
+	require.NoError(t, err)
+	require.Len(t, deliveries, len(events.RegisteredEvents()))
+	for _, d := range deliveries {
+		assert.NoError(t, d.Err)
+		assert.Equal(t, []int{http.StatusOK}, d.Attempts, d.Event.GetName())
+	}
+	assert.Equal(t, events.RegisteredEvents(), received)
+}
+
+func TestSimulatorFaults(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(NewVerifier(testSigningKey), events.NewDispatcher(), nil))
+	defer srv.Close()
+
+	sim := NewSimulator(testSigningKey, &SimulatorOptions{
+		Faults: []Fault{FaultNone, FaultBadSignature, FaultExpiredTimestamp, FaultReplay, FaultMalformed},
+	})
+	deliveries, err := sim.SendAll(context.Background(), srv.URL,
+		events.EventDelivered, events.EventDelivered, events.EventDelivered, events.EventDelivered, events.EventDelivered)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{http.StatusOK}, deliveries[0].Attempts)
+	assert.Equal(t, []int{http.StatusNotAcceptable}, deliveries[1].Attempts)
+	assert.Equal(t, []int{http.StatusNotAcceptable}, deliveries[2].Attempts)
+	assert.Equal(t, []int{http.StatusOK, http.StatusNotAcceptable}, deliveries[3].Attempts)
+	assert.Equal(t, []int{http.StatusNotAcceptable}, deliveries[4].Attempts)
+	assert.Equal(t, FaultReplay, deliveries[3].Fault)
+}
+
+func TestParseFault(t *testing.T) {
+	for _, f := range []Fault{FaultNone, FaultBadSignature, FaultExpiredTimestamp, FaultReplay, FaultMalformed} {
+		parsed, err := ParseFault(string(f))
+		require.NoError(t, err)
+		assert.Equal(t, f, parsed)
+	}
+	_, err := ParseFault("bad-sig")
+	assert.EqualError(t, err, "unknown fault 'bad-sig'")
+}
+
+func TestSimulatorRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sim := NewSimulator(testSigningKey, &SimulatorOptions{
+		RetrySchedule: []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond},
+	})
+	e, err := SampleEvent(events.EventOpened)
+	require.NoError(t, err)
+
+	d := sim.Send(context.Background(), srv.URL, e)
+	assert.NoError(t, d.Err)
+	assert.Equal(t, []int{503, 503, 200}, d.Attempts)
+	assert.Equal(t, http.StatusOK, d.Status())
+
+	// Without retries left, the last error is returned.
+	calls.Store(0)
+	sim = NewSimulator(testSigningKey, &SimulatorOptions{RetrySchedule: []time.Duration{time.Millisecond}})
+	d = sim.Send(context.Background(), srv.URL, e)
+	assert.Error(t, d.Err)
+	assert.Equal(t, []int{503, 503}, d.Attempts)
+}
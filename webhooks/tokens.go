@@ -0,0 +1,98 @@
+package webhooks
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultTokenStoreSize is the capacity of the token store of a Verifier created with NewVerifier.
+const DefaultTokenStoreSize = 100_000
+
+// TokenStore remembers the tokens of the verified signatures, to reject replayed requests.
+// Implement it with a shared store, e.g. Redis, when several instances receive the webhooks.
+type TokenStore interface {
+	// Add records the token until it expires, and reports whether it was already recorded and not expired.
+	// A zero expiration time never expires.
+	Add(ctx context.Context, token string, expires time.Time) (seen bool, err error)
+	// Remove forgets the token, so the request can be retried after its handling failed.
+	// Removing an unknown token is not an error.
+	Remove(ctx context.Context, token string) error
+}
+
+// MemoryTokenStore is an in-memory TokenStore. It evicts the least recently used tokens
+// when its capacity is reached, so the capacity must exceed the number of webhooks received
+// during the tolerance of the Verifier.
+type MemoryTokenStore struct {
+	mutex    sync.Mutex
+	capacity int
+	tokens   map[string]*list.Element
+	lru      *list.List
+	now      func() time.Time
+}
+
+type storedToken struct {
+	token   string
+	expires time.Time
+}
+
+// NewMemoryTokenStore creates an in-memory token store of the given capacity.
+func NewMemoryTokenStore(capacity int) *MemoryTokenStore {
+	return &MemoryTokenStore{
+		capacity: max(capacity, 1),
+		tokens:   make(map[string]*list.Element),
+		lru:      list.New(),
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryTokenStore) Add(_ context.Context, token string, expires time.Time) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	if el, ok := s.tokens[token]; ok {
+		stored := el.Value.(*storedToken)
+		seen := stored.expires.IsZero() || now.Before(stored.expires)
+		stored.expires = expires
+		s.lru.MoveToFront(el)
+		return seen, nil
+	}
+
+	s.tokens[token] = s.lru.PushFront(&storedToken{token: token, expires: expires})
+	for s.lru.Len() > s.capacity || s.expired(s.lru.Back(), now) {
+		s.remove(s.lru.Back())
+	}
+	return false, nil
+}
+
+func (s *MemoryTokenStore) Remove(_ context.Context, token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if el, ok := s.tokens[token]; ok {
+		s.remove(el)
+	}
+	return nil
+}
+
+// Len returns the number of tokens in the store, including the expired tokens not evicted yet.
+func (s *MemoryTokenStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lru.Len()
+}
+
+func (s *MemoryTokenStore) expired(el *list.Element, now time.Time) bool {
+	if el == nil {
+		return false
+	}
+	expires := el.Value.(*storedToken).expires
+	return !expires.IsZero() && !now.Before(expires)
+}
+
+func (s *MemoryTokenStore) remove(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.tokens, el.Value.(*storedToken).token)
+}
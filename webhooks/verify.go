@@ -0,0 +1,253 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+)
+
+// DefaultTolerance is the maximum difference between the timestamp of a signature and the current time
+// accepted by a Verifier created with NewVerifier.
+const DefaultTolerance = 5 * time.Minute
+
+// MaxPreviousSigningKeys is the number of previous signing keys kept by Rotate. Mailgun stops retrying
+// a webhook after 8 hours, so the keys rotated out long ago no longer sign any request.
+const MaxPreviousSigningKeys = 2
+
+var (
+	// ErrInvalidSignature is returned when the signature does not match the timestamp and token.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrExpiredTimestamp is returned when the timestamp of a valid signature is outside the tolerance.
+	ErrExpiredTimestamp = errors.New("webhook timestamp is outside the tolerance")
+	// ErrReplayedToken is returned when the token of a valid signature was already used.
+	ErrReplayedToken = errors.New("webhook token was already used")
+
+	// errTokenStore wraps the errors of the token store, see StatusCode.
+	errTokenStore = errors.New("while recording webhook token")
+)
+
+// Verifier verifies the signatures of the webhook requests sent by Mailgun.
+// Along with the signature, it checks the timestamp and the token to reject replayed requests,
+// as recommended by the Mailgun documentation.
+//
+// Signatures are checked against the current signing key, then against the previous keys, so the key
+// can be rotated without rejecting the webhooks signed with the previous key. The keys are set by
+// NewVerifier, use Rotate and RetirePreviousKeys to change the keys of a verifier in use.
+type Verifier struct {
+	// OnKeyMatch is called with the index of the key matching a signature, e.g. to export metrics:
+	// 0 for the signing key, and i+1 for the i-th previous key.
+	OnKeyMatch func(index int)
+	// Tolerance is the maximum clock skew, i.e. the maximum difference between the timestamp of a signature
+	// and the current time, in both directions. Zero disables the check.
+	Tolerance time.Duration
+	// Tokens remembers the tokens of the verified signatures until their timestamp is outside the tolerance.
+	// A token used twice is rejected with ErrReplayedToken, unless it was forgotten with Forget. Nil disables the check.
+	Tokens TokenStore
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+
+	mutex        sync.RWMutex
+	signingKey   string
+	previousKeys []string
+	// matches has a counter per key, replaced along with the keys.
+	matches []atomic.Uint64
+}
+
+// NewVerifier creates a verifier of the signatures made with the signing key or the previous keys,
+// with the default tolerance and an in-memory token store.
+func NewVerifier(signingKey string, previousKeys ...string) *Verifier {
+	return &Verifier{
+		signingKey:   signingKey,
+		previousKeys: previousKeys,
+		matches:      make([]atomic.Uint64, 1+len(previousKeys)),
+		Tolerance:    DefaultTolerance,
+		Tokens:       NewMemoryTokenStore(DefaultTokenStoreSize),
+	}
+}
+
+// SigningKey returns the current signing key.
+func (v *Verifier) SigningKey() string {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	return v.signingKey
+}
+
+// PreviousSigningKeys returns the previous signing keys also accepted, most recent first.
+func (v *Verifier) PreviousSigningKeys() []string {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	return slices.Clone(v.previousKeys)
+}
+
+// Rotate makes the key the current signing key, the current key becomes the most recent previous key.
+// Only the MaxPreviousSigningKeys most recent previous keys are kept. The key matches are reset.
+// It is safe for concurrent use with Verify.
+func (v *Verifier) Rotate(signingKey string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.signingKey != "" {
+		v.previousKeys = append([]string{v.signingKey}, v.previousKeys...)
+		v.previousKeys = v.previousKeys[:min(len(v.previousKeys), MaxPreviousSigningKeys)]
+	}
+	v.signingKey = signingKey
+	v.matches = make([]atomic.Uint64, 1+len(v.previousKeys))
+}
+
+// RetirePreviousKeys stops accepting the previous signing keys, e.g. once KeyMatches shows that
+// they no longer match. It is safe for concurrent use with Verify.
+func (v *Verifier) RetirePreviousKeys() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.previousKeys = nil
+	v.matches = v.matches[:min(len(v.matches), 1)]
+}
+
+// KeyMatches returns the number of signatures matched by each key since the last rotation,
+// indexed as in OnKeyMatch.
+func (v *Verifier) KeyMatches() []uint64 {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	matches := make([]uint64, 1+len(v.previousKeys))
+	for i := range v.matches {
+		matches[i] = v.matches[i].Load()
+	}
+	return matches
+}
+
+// Verify returns nil if the signature is valid, ErrInvalidSignature if it does not match,
+// ErrExpiredTimestamp if its timestamp is outside the tolerance, or ErrReplayedToken if its token was already used.
+func (v *Verifier) Verify(sig mtypes.Signature) error {
+	return v.VerifyContext(context.Background(), sig)
+}
+
+// VerifyContext is Verify with a context passed to the token store.
+func (v *Verifier) VerifyContext(ctx context.Context, sig mtypes.Signature) error {
+	if err := v.verifySignature(sig); err != nil {
+		return err
+	}
+
+	var expires time.Time
+	if v.Tolerance > 0 {
+		ts, err := strconv.ParseInt(sig.TimeStamp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid timestamp '%s'", ErrExpiredTimestamp, sig.TimeStamp)
+		}
+		if d := v.now().Sub(time.Unix(ts, 0)).Abs(); d > v.Tolerance {
+			return fmt.Errorf("%w: timestamp is %s away", ErrExpiredTimestamp, d.Round(time.Second))
+		}
+		// Past this time, the timestamp check rejects the token.
+		expires = time.Unix(ts, 0).Add(v.Tolerance)
+	}
+
+	if v.Tokens != nil {
+		seen, err := v.Tokens.Add(ctx, sig.Token, expires)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errTokenStore, err)
+		}
+		if seen {
+			return ErrReplayedToken
+		}
+	}
+	return nil
+}
+
+// Forget removes the token of a verified signature from the token store, so that Mailgun can retry
+// the request if its handling failed. The handlers of this package forget the tokens of the requests
+// they respond to with a status code that Mailgun retries.
+func (v *Verifier) Forget(ctx context.Context, sig mtypes.Signature) error {
+	if v.Tokens == nil {
+		return nil
+	}
+	if err := v.Tokens.Remove(ctx, sig.Token); err != nil {
+		return fmt.Errorf("while removing webhook token: %w", err)
+	}
+	return nil
+}
+
+// ForgetRetried forgets the token of a request failing with a status code that Mailgun retries,
+// i.e. other than 406 Not Acceptable, so the retry is not rejected as replayed. It returns err,
+// joined with the error of the token store.
+func (v *Verifier) ForgetRetried(ctx context.Context, sig mtypes.Signature, status int, err error) error {
+	if status == http.StatusNotAcceptable {
+		return err
+	}
+	// The request may have failed because it was cancelled: forget the token anyway.
+	return errors.Join(err, v.Forget(context.WithoutCancel(ctx), sig))
+}
+
+// verifySignature checks the signature against every key.
+func (v *Verifier) verifySignature(sig mtypes.Signature) error {
+	index, err := v.match(sig)
+	if err != nil {
+		return err
+	}
+	if v.OnKeyMatch != nil {
+		v.OnKeyMatch(index)
+	}
+	return nil
+}
+
+// match returns the index of the key matching the signature, and counts the match.
+func (v *Verifier) match(sig mtypes.Signature) (int, error) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	if v.signingKey == "" {
+		return 0, errors.New("webhook signing key is not set")
+	}
+	signature, err := hex.DecodeString(sig.Signature)
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+
+	for i, key := range append([]string{v.signingKey}, v.previousKeys...) {
+		if hmac.Equal(signature, sign(key, sig.TimeStamp, sig.Token)) {
+			if i < len(v.matches) {
+				v.matches[i].Add(1)
+			}
+			return i, nil
+		}
+	}
+	return 0, ErrInvalidSignature
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+// Sign returns the signature of the timestamp and token made with the signing key, as Mailgun does.
+// It is useful to test webhook handlers.
+func Sign(signingKey, timestamp, token string) mtypes.Signature {
+	return mtypes.Signature{
+		TimeStamp: timestamp,
+		Token:     token,
+		Signature: hex.EncodeToString(sign(signingKey, timestamp, token)),
+	}
+}
+
+func sign(signingKey, timestamp, token string) []byte {
+	h := hmac.New(sha256.New, []byte(signingKey))
+	_, _ = io.WriteString(h, timestamp)
+	_, _ = io.WriteString(h, token)
+	return h.Sum(nil)
+}
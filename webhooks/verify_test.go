@@ -0,0 +1,156 @@
+package webhooks
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSigningKey = "WEBHOOK_SIGNING_KEY"
+
+func TestVerifier(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	v := NewVerifier(testSigningKey)
+	v.Now = func() time.Time { return now }
+	v.Tokens = nil
+	timestamp := func(d time.Duration) string {
+		return strconv.FormatInt(now.Add(d).Unix(), 10)
+	}
+
+	require.NoError(t, v.Verify(Sign(testSigningKey, timestamp(0), "token")))
+	require.NoError(t, v.Verify(Sign(testSigningKey, timestamp(-4*time.Minute), "token")))
+
+	sig := Sign(testSigningKey, timestamp(0), "token")
+	sig.Token = "other"
+	assert.ErrorIs(t, v.Verify(sig), ErrInvalidSignature)
+	assert.ErrorIs(t, v.Verify(Sign("other key", timestamp(0), "token")), ErrInvalidSignature)
+	sig.Signature = "not hex"
+	assert.ErrorIs(t, v.Verify(sig), ErrInvalidSignature)
+
+	assert.ErrorIs(t, v.Verify(Sign(testSigningKey, timestamp(-6*time.Minute), "token")), ErrExpiredTimestamp)
+	assert.ErrorIs(t, v.Verify(Sign(testSigningKey, timestamp(6*time.Minute), "token")), ErrExpiredTimestamp)
+	assert.ErrorIs(t, v.Verify(Sign(testSigningKey, "yesterday", "token")), ErrExpiredTimestamp)
+
+	v.Tolerance = 0
+	require.NoError(t, v.Verify(Sign(testSigningKey, "123456789", "token")))
+
+	assert.Error(t, NewVerifier("").Verify(Sign(testSigningKey, timestamp(0), "token")))
+}
+
+func TestVerifierReplayedToken(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	store := NewMemoryTokenStore(10)
+	store.now = func() time.Time { return now }
+	v := NewVerifier(testSigningKey)
+	v.Now = func() time.Time { return now }
+	v.Tokens = store
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	require.NoError(t, v.Verify(Sign(testSigningKey, timestamp, "token-1")))
+	assert.ErrorIs(t, v.Verify(Sign(testSigningKey, timestamp, "token-1")), ErrReplayedToken)
+	require.NoError(t, v.Verify(Sign(testSigningKey, timestamp, "token-2")))
+
+	// Invalid signatures do not record their token.
+	sig := Sign(testSigningKey, timestamp, "token-3")
+	sig.Signature = Sign("other key", timestamp, "token-3").Signature
+	assert.ErrorIs(t, v.Verify(sig), ErrInvalidSignature)
+	require.NoError(t, v.Verify(Sign(testSigningKey, timestamp, "token-3")))
+
+	// Once the timestamp is outside the tolerance, the timestamp check rejects the replayed token.
+	now = now.Add(DefaultTolerance + time.Second)
+	assert.ErrorIs(t, v.Verify(Sign(testSigningKey, timestamp, "token-1")), ErrExpiredTimestamp)
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	store := NewMemoryTokenStore(3)
+	store.now = func() time.Time { return now }
+
+	add := func(token string, expires time.Time) bool {
+		seen, err := store.Add(ctx, token, expires)
+		require.NoError(t, err)
+		return seen
+	}
+
+	assert.False(t, add("a", now.Add(time.Minute)))
+	assert.True(t, add("a", now.Add(time.Minute)))
+	assert.False(t, add("b", time.Time{}))
+	assert.False(t, add("c", now.Add(time.Minute)))
+
+	// The least recently used token is evicted.
+	assert.False(t, add("d", now.Add(time.Minute)))
+	assert.Equal(t, 3, store.Len())
+	assert.True(t, add("b", time.Time{}))
+	assert.False(t, add("a", now.Add(time.Minute)))
+
+	// Expired tokens are not seen.
+	now = now.Add(2 * time.Minute)
+	assert.False(t, add("a", now.Add(time.Minute)))
+	assert.True(t, add("a", now.Add(time.Minute)))
+	assert.True(t, add("b", time.Time{}))
+
+	// Removed tokens are not seen.
+	require.NoError(t, store.Remove(ctx, "a"))
+	require.NoError(t, store.Remove(ctx, "unknown"))
+	assert.False(t, add("a", now.Add(time.Minute)))
+}
+
+func TestMemoryTokenStoreConcurrent(t *testing.T) {
+	store := NewMemoryTokenStore(1000)
+	var seen atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				ok, err := store.Add(context.Background(), strconv.Itoa(i), time.Time{})
+				assert.NoError(t, err)
+				if ok {
+					seen.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(900), seen.Load())
+}
+
+func TestVerifierPreviousKeys(t *testing.T) {
+	v := NewVerifier("key-2", "key-1")
+	v.Tolerance = 0
+	v.Tokens = nil
+	var matched []int
+	v.OnKeyMatch = func(index int) {
+		matched = append(matched, index)
+	}
+
+	require.NoError(t, v.Verify(Sign("key-1", "123456789", "token")))
+	require.NoError(t, v.Verify(Sign("key-2", "123456789", "token")))
+	assert.ErrorIs(t, v.Verify(Sign("key-0", "123456789", "token")), ErrInvalidSignature)
+	assert.Equal(t, []int{1, 0}, matched)
+	assert.Equal(t, []uint64{1, 1}, v.KeyMatches())
+
+	v.Rotate("key-3")
+	assert.Equal(t, "key-3", v.SigningKey())
+	assert.Equal(t, []string{"key-2", "key-1"}, v.PreviousSigningKeys())
+	assert.Equal(t, []uint64{0, 0, 0}, v.KeyMatches())
+	require.NoError(t, v.Verify(Sign("key-1", "123456789", "token")))
+	assert.Equal(t, []uint64{0, 0, 1}, v.KeyMatches())
+
+	v.Rotate("key-4")
+	assert.Equal(t, []string{"key-3", "key-2"}, v.PreviousSigningKeys())
+	v.Rotate("key-3")
+
+	v.RetirePreviousKeys()
+	assert.ErrorIs(t, v.Verify(Sign("key-2", "123456789", "token")), ErrInvalidSignature)
+	require.NoError(t, v.Verify(Sign("key-3", "123456789", "token")))
+	assert.Equal(t, []uint64{1}, v.KeyMatches())
+}
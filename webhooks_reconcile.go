@@ -0,0 +1,216 @@
+package mailgun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// WebhookAction is the action of a change of a webhook reconciliation plan.
+type WebhookAction string
+
+const (
+	WebhookCreate WebhookAction = "create"
+	WebhookUpdate WebhookAction = "update"
+	WebhookDelete WebhookAction = "delete"
+)
+
+// WebhookChange is a change of a webhook reconciliation plan.
+type WebhookChange struct {
+	Action WebhookAction
+	// Kind is the webhook kind, e.g. "delivered" or "permanent_fail".
+	Kind string
+	// From are the current URLs, empty for a creation.
+	From []string
+	// To are the desired URLs, empty for a deletion.
+	To []string
+}
+
+// WebhookPlan is the changes reconciling the webhooks of a domain with the desired webhooks.
+type WebhookPlan struct {
+	Domain string
+	// SubaccountID is the subaccount the domain belongs to, if any.
+	SubaccountID string
+	// Changes are sorted by webhook kind.
+	Changes []WebhookChange
+	// Applied reports whether the changes were applied, false for a dry run.
+	Applied bool
+}
+
+// String returns the plan in a human-readable format, one change per line:
+//
+//	example.com:
+//	  + delivered [https://example.com/hooks]
+//	  ~ opened [https://old.example.com/hooks] -> [https://example.com/hooks]
+//	  - clicked [https://example.com/clicks]
+func (p *WebhookPlan) String() string {
+	var b strings.Builder
+	b.WriteString(p.Domain)
+	if p.SubaccountID != "" {
+		fmt.Fprintf(&b, " (subaccount %s)", p.SubaccountID)
+	}
+	b.WriteString(":\n")
+	if len(p.Changes) == 0 {
+		b.WriteString("  no changes\n")
+	}
+	for _, c := range p.Changes {
+		switch c.Action {
+		case WebhookCreate:
+			fmt.Fprintf(&b, "  + %s %v\n", c.Kind, c.To)
+		case WebhookUpdate:
+			fmt.Fprintf(&b, "  ~ %s %v -> %v\n", c.Kind, c.From, c.To)
+		case WebhookDelete:
+			fmt.Fprintf(&b, "  - %s %v\n", c.Kind, c.From)
+		}
+	}
+	return b.String()
+}
+
+// ReconcileWebhooksOptions modifies the behavior of ReconcileWebhooks().
+type ReconcileWebhooksOptions struct {
+	// DryRun computes the plan without applying it.
+	DryRun bool
+	// KeepUnlisted keeps the webhooks whose kind is not in the desired webhooks, instead of deleting them.
+	KeepUnlisted bool
+	// Output, if set, receives the plan of each domain before it is applied, see WebhookPlan.String.
+	Output io.Writer
+}
+
+// WebhookTarget is a domain whose webhooks are reconciled by ReconcileWebhooksForDomains().
+type WebhookTarget struct {
+	Domain string
+	// SubaccountID is the subaccount owning the domain, empty for the primary account.
+	SubaccountID string
+}
+
+// ReconcileWebhooks makes the webhooks of the domain match the desired webhooks, a map of
+// webhook kinds to URLs. URLs are compared regardless of their order. It returns the plan
+// of the changes, applied unless opts.DryRun is set.
+//
+//	plan, err := mg.ReconcileWebhooks(ctx, "example.com", map[string][]string{
+//		"delivered":      {"https://example.com/hooks"},
+//		"permanent_fail": {"https://example.com/hooks"},
+//	}, &mailgun.ReconcileWebhooksOptions{DryRun: true, Output: os.Stdout})
+func (mg *Client) ReconcileWebhooks(ctx context.Context, domain string, desired map[string][]string,
+	opts *ReconcileWebhooksOptions,
+) (*WebhookPlan, error) {
+	return mg.reconcileWebhooks(ctx, WebhookTarget{Domain: domain}, desired, opts)
+}
+
+// ReconcileWebhooksForDomains reconciles the webhooks of each domain with the same desired webhooks,
+// see ReconcileWebhooks. The domains of subaccounts are managed on behalf of their subaccount.
+// It continues after an error, and returns the plans of every domain along with the joined errors.
+func (mg *Client) ReconcileWebhooksForDomains(ctx context.Context, targets []WebhookTarget,
+	desired map[string][]string, opts *ReconcileWebhooksOptions,
+) ([]WebhookPlan, error) {
+	var plans []WebhookPlan
+	var errs []error
+	for _, target := range targets {
+		plan, err := mg.reconcileWebhooks(ctx, target, desired, opts)
+		if plan != nil {
+			plans = append(plans, *plan)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return plans, errors.Join(errs...)
+}
+
+func (mg *Client) reconcileWebhooks(ctx context.Context, target WebhookTarget, desired map[string][]string,
+	opts *ReconcileWebhooksOptions,
+) (*WebhookPlan, error) {
+	var o ReconcileWebhooksOptions
+	if opts != nil {
+		o = *opts
+	}
+	client := mg
+	if target.SubaccountID != "" {
+		client = mg.onBehalfOf(target.SubaccountID)
+	}
+
+	current, err := client.ListWebhooks(ctx, target.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("while listing webhooks of domain '%s': %w", target.Domain, err)
+	}
+
+	plan := WebhookPlan{
+		Domain:       target.Domain,
+		SubaccountID: target.SubaccountID,
+		Changes:      planWebhookChanges(current, desired, o.KeepUnlisted),
+	}
+	if o.Output != nil {
+		if _, err := io.WriteString(o.Output, plan.String()); err != nil {
+			return &plan, fmt.Errorf("while writing webhooks plan: %w", err)
+		}
+	}
+	if o.DryRun {
+		return &plan, nil
+	}
+
+	for _, c := range plan.Changes {
+		switch c.Action {
+		case WebhookCreate:
+			err = client.CreateWebhook(ctx, target.Domain, c.Kind, c.To)
+		case WebhookUpdate:
+			err = client.UpdateWebhook(ctx, target.Domain, c.Kind, c.To)
+		case WebhookDelete:
+			err = client.DeleteWebhook(ctx, target.Domain, c.Kind)
+		}
+		if err != nil {
+			return &plan, fmt.Errorf("failed to %s webhook '%s' of domain '%s': %w", c.Action, c.Kind, target.Domain, err)
+		}
+	}
+	plan.Applied = true
+	return &plan, nil
+}
+
+// planWebhookChanges returns the changes from the current to the desired webhooks, sorted by kind.
+func planWebhookChanges(current, desired map[string][]string, keepUnlisted bool) []WebhookChange {
+	var changes []WebhookChange
+	for _, kind := range slices.Sorted(maps.Keys(desired)) {
+		to := desired[kind]
+		from, ok := current[kind]
+		switch {
+		case len(to) == 0 && ok:
+			changes = append(changes, WebhookChange{Action: WebhookDelete, Kind: kind, From: from})
+		case len(to) == 0:
+		case !ok:
+			changes = append(changes, WebhookChange{Action: WebhookCreate, Kind: kind, To: to})
+		case !sameURLs(from, to):
+			changes = append(changes, WebhookChange{Action: WebhookUpdate, Kind: kind, From: from, To: to})
+		}
+	}
+
+	if !keepUnlisted {
+		for _, kind := range slices.Sorted(maps.Keys(current)) {
+			if _, ok := desired[kind]; !ok {
+				changes = append(changes, WebhookChange{Action: WebhookDelete, Kind: kind, From: current[kind]})
+			}
+		}
+	}
+
+	slices.SortStableFunc(changes, func(a, b WebhookChange) int {
+		return strings.Compare(a.Kind, b.Kind)
+	})
+	return changes
+}
+
+func sameURLs(a, b []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
+
+// onBehalfOf returns a copy of the client performing the requests on behalf of the subaccount.
+func (mg *Client) onBehalfOf(subaccountID string) *Client {
+	c := *mg
+	c.overrideHeaders = maps.Clone(mg.overrideHeaders)
+	c.SetOnBehalfOfSubaccount(subaccountID)
+	return &c
+}
@@ -0,0 +1,172 @@
+package mailgun_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mailgun/mailgun-go/v5"
+	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhooksServer stores the webhooks of each domain, by subaccount.
+type webhooksServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	webhooks map[string]map[string][]string
+	writes   int
+}
+
+func newWebhooksServer(t *testing.T, webhooks map[string]map[string][]string) *webhooksServer {
+	s := &webhooksServer{webhooks: webhooks}
+	key := func(r *http.Request) string {
+		return r.Header.Get(mailgun.OnBehalfOfHeader) + "/" + r.PathValue("domain")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v3/domains/{domain}/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		resp := mtypes.WebHooksListResponse{Webhooks: map[string]mtypes.UrlOrUrls{}}
+		for kind, urls := range s.webhooks[key(r)] {
+			resp.Webhooks[kind] = mtypes.UrlOrUrls{Urls: urls}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("POST /v3/domains/{domain}/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		s.set(key(r), r.FormValue("id"), r)
+	})
+	mux.HandleFunc("PUT /v3/domains/{domain}/webhooks/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		s.set(key(r), r.PathValue("kind"), r)
+	})
+	mux.HandleFunc("DELETE /v3/domains/{domain}/webhooks/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.writes++
+		delete(s.webhooks[key(r)], r.PathValue("kind"))
+		_, _ = w.Write([]byte(`{"message": "success"}`))
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *webhooksServer) set(key, kind string, r *http.Request) {
+	_ = r.ParseForm()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.writes++
+	if s.webhooks[key] == nil {
+		s.webhooks[key] = map[string][]string{}
+	}
+	s.webhooks[key][kind] = r.Form["url"]
+}
+
+func TestReconcileWebhooks(t *testing.T) {
+	srv := newWebhooksServer(t, map[string]map[string][]string{
+		"/example.com": {
+			"delivered": {"https://b.example.com", "https://a.example.com"},
+			"opened":    {"https://old.example.com"},
+			"clicked":   {"https://a.example.com"},
+		},
+	})
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+	ctx := context.Background()
+
+	desired := map[string][]string{
+		"delivered":      {"https://a.example.com", "https://b.example.com"},
+		"opened":         {"https://a.example.com"},
+		"permanent_fail": {"https://a.example.com"},
+	}
+
+	var out bytes.Buffer
+	plan, err := mg.ReconcileWebhooks(ctx, "example.com", desired,
+		&mailgun.ReconcileWebhooksOptions{DryRun: true, Output: &out})
+	require.NoError(t, err)
+	assert.False(t, plan.Applied)
+	assert.Equal(t, []mailgun.WebhookChange{
+		{Action: mailgun.WebhookDelete, Kind: "clicked", From: []string{"https://a.example.com"}},
+		{Action: mailgun.WebhookUpdate, Kind: "opened",
+			From: []string{"https://old.example.com"}, To: []string{"https://a.example.com"}},
+		{Action: mailgun.WebhookCreate, Kind: "permanent_fail", To: []string{"https://a.example.com"}},
+	}, plan.Changes)
+	assert.Equal(t, "example.com:\n"+
+		"  - clicked [https://a.example.com]\n"+
+		"  ~ opened [https://old.example.com] -> [https://a.example.com]\n"+
+		"  + permanent_fail [https://a.example.com]\n", out.String())
+	assert.Zero(t, srv.writes)
+
+	plan, err = mg.ReconcileWebhooks(ctx, "example.com", desired, nil)
+	require.NoError(t, err)
+	assert.True(t, plan.Applied)
+	assert.Len(t, plan.Changes, 3)
+	assert.Equal(t, map[string][]string{
+		"delivered":      {"https://b.example.com", "https://a.example.com"},
+		"opened":         {"https://a.example.com"},
+		"permanent_fail": {"https://a.example.com"},
+	}, srv.webhooks["/example.com"])
+
+	// Reconciled webhooks have no changes.
+	plan, err = mg.ReconcileWebhooks(ctx, "example.com", desired, nil)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Changes)
+	assert.Equal(t, "example.com:\n  no changes\n", plan.String())
+}
+
+func TestReconcileWebhooksKeepUnlisted(t *testing.T) {
+	srv := newWebhooksServer(t, map[string]map[string][]string{
+		"/example.com": {"clicked": {"https://a.example.com"}, "opened": {"https://a.example.com"}},
+	})
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	// An empty URL list deletes the webhook even when unlisted webhooks are kept.
+	plan, err := mg.ReconcileWebhooks(context.Background(), "example.com",
+		map[string][]string{"opened": nil}, &mailgun.ReconcileWebhooksOptions{KeepUnlisted: true})
+	require.NoError(t, err)
+	assert.Equal(t, []mailgun.WebhookChange{
+		{Action: mailgun.WebhookDelete, Kind: "opened", From: []string{"https://a.example.com"}},
+	}, plan.Changes)
+	assert.Equal(t, map[string][]string{"clicked": {"https://a.example.com"}}, srv.webhooks["/example.com"])
+}
+
+func TestReconcileWebhooksForDomains(t *testing.T) {
+	srv := newWebhooksServer(t, map[string]map[string][]string{
+		"/a.example.com":              {"delivered": {"https://old.example.com"}},
+		"subaccount/b.example.com":    {},
+		"subaccount/a.example.com":    {"opened": {"https://old.example.com"}},
+		"other-account/b.example.com": {"opened": {"https://old.example.com"}},
+	})
+	mg := mailgun.NewMailgun(testKey)
+	require.NoError(t, mg.SetAPIBase(srv.URL))
+
+	desired := map[string][]string{"delivered": {"https://example.com/hooks"}}
+	plans, err := mg.ReconcileWebhooksForDomains(context.Background(), []mailgun.WebhookTarget{
+		{Domain: "a.example.com"},
+		{Domain: "b.example.com", SubaccountID: "subaccount"},
+	}, desired, nil)
+	require.NoError(t, err)
+	require.Len(t, plans, 2)
+	assert.Equal(t, "subaccount", plans[1].SubaccountID)
+	assert.Equal(t, mailgun.WebhookCreate, plans[1].Changes[0].Action)
+
+	assert.Equal(t, desired, srv.webhooks["/a.example.com"])
+	assert.Equal(t, desired, srv.webhooks["subaccount/b.example.com"])
+	// Other domains and subaccounts are untouched.
+	assert.Equal(t, map[string][]string{"opened": {"https://old.example.com"}}, srv.webhooks["subaccount/a.example.com"])
+	assert.Equal(t, map[string][]string{"opened": {"https://old.example.com"}}, srv.webhooks["other-account/b.example.com"])
+
+	// The subaccount header is not left on the client.
+	plan, err := mg.ReconcileWebhooks(context.Background(), "b.example.com", desired,
+		&mailgun.ReconcileWebhooksOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, mailgun.WebhookCreate, plan.Changes[0].Action)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/mailgun/mailgun-go/v5"
 	"github.com/mailgun/mailgun-go/v5/mtypes"
+	"github.com/mailgun/mailgun-go/v5/webhooks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -117,3 +118,64 @@ func getSignatureFields(key string, signed bool) map[string]string {
 
 	return fields
 }
+
+func TestVerifyWebhookSignatureMultipleKeys(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	mg.SetWebhookSigningKeys("new key", "", testWebhookSigningKey)
+	assert.Equal(t, "new key", mg.WebhookSigningKey())
+	assert.Equal(t, []string{"new key", testWebhookSigningKey}, mg.WebhookSigningKeys())
+
+	for _, key := range []string{"new key", testWebhookSigningKey} {
+		verified, err := mg.VerifyWebhookSignature(webhooks.Sign(key, "123456789", "token"))
+		require.NoError(t, err)
+		assert.True(t, verified)
+	}
+
+	verified, err := mg.VerifyWebhookSignature(webhooks.Sign("retired key", "123456789", "token"))
+	require.NoError(t, err)
+	assert.False(t, verified)
+	assert.Equal(t, []uint64{1, 1}, mg.WebhookKeyMatches())
+}
+
+func TestRotateWebhookSigningKey(t *testing.T) {
+	mg := mailgun.NewMailgun(testKey)
+	err := mg.SetAPIBase(server.URL())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	resp, err := mg.GetWebhookSigningKey(ctx)
+	require.NoError(t, err)
+	previous := resp.SigningKey
+	require.NotEmpty(t, previous)
+
+	v := webhooks.NewVerifier(previous)
+	v.Tolerance = 0
+	v.Tokens = nil
+	key, err := mg.RotateWebhookSigningKey(ctx, v)
+	require.NoError(t, err)
+	assert.NotEqual(t, previous, key)
+	assert.Equal(t, server.WebhookSigningKey(), key)
+	assert.Equal(t, []string{key, previous}, mg.WebhookSigningKeys())
+
+	// Webhooks signed before and after the rotation are accepted.
+	for _, signingKey := range []string{previous, key, key} {
+		verified, err := mg.VerifyWebhookSignature(webhooks.Sign(signingKey, "123456789", "token"))
+		require.NoError(t, err)
+		assert.True(t, verified)
+		require.NoError(t, v.Verify(webhooks.Sign(signingKey, "123456789", "token")))
+	}
+	assert.Equal(t, []uint64{2, 1}, v.KeyMatches())
+	assert.Equal(t, []uint64{2, 1}, mg.WebhookKeyMatches())
+
+	// Only the most recent previous keys are kept.
+	for range webhooks.MaxPreviousSigningKeys {
+		_, err = mg.RotateWebhookSigningKey(ctx)
+		require.NoError(t, err)
+	}
+	assert.Len(t, mg.WebhookSigningKeys(), 1+webhooks.MaxPreviousSigningKeys)
+	assert.NotContains(t, mg.WebhookSigningKeys(), previous)
+
+	v.RetirePreviousKeys()
+	assert.ErrorIs(t, v.Verify(webhooks.Sign(previous, "123456789", "token")), webhooks.ErrInvalidSignature)
+	assert.Equal(t, []uint64{2}, v.KeyMatches())
+}